@@ -3,12 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/pkg/action"
 )
 
 var (
@@ -17,10 +19,27 @@ var (
 	chartDir       string
 	baseBranch     string
 	pagesBranch    string
+	backend        string
 	versionPattern string
 	cleanAllIndex  bool
+	match          []string
+	keepLast       int
+	constraintFor  string
+	constraint     string
+	pruneKeep      int
+	dryRun         bool
+	force          bool
+	concurrency    int
+	registries     []string
+	keyringPath    string
+	labelSelector  string
 	logLevel       string
 	logger         logr.Logger
+
+	labelChart   string
+	labelVersion string
+	labelPairs   []string
+	labelKeys    []string
 )
 
 func main() {
@@ -61,14 +80,71 @@ Provides functionality to:
 	cleanup.Flags().StringVarP(&repository, "repo", "r", "", "GitHub repository")
 	cleanup.Flags().StringVarP(&chartDir, "chart-dir", "d", "charts", "Directory containing Helm charts")
 	cleanup.Flags().StringVarP(&pagesBranch, "pages-branch", "p", "gh-pages", "Branch containing the chart index")
+	cleanup.Flags().StringVar(&backend, "backend", "", "Index backend URL (e.g. oci://ghcr.io/org/charts or git+https://...#gh-pages); defaults to --pages-branch")
 	cleanup.Flags().StringVarP(&baseBranch, "base-branch", "b", "main", "Base branch to compare changes against")
 	cleanup.Flags().StringVarP(&versionPattern, "version-pattern", "v", "^0\\.0\\.0-dev$", "Regex pattern to match chart versions for deletion")
 	cleanup.Flags().BoolVar(&cleanAllIndex, "all", false, "Clean all chart index entries (ignore version pattern)")
+	cleanup.Flags().StringArrayVar(&match, "match", nil, "Clean index entries matching chartName@constraint (semver range, glob, or re:regex); repeatable")
+	cleanup.Flags().IntVar(&keepLast, "keep-last", 0, "Retain the newest N semver versions per chart regardless of --match (0 disables)")
+	cleanup.Flags().StringVar(&constraintFor, "chart", "", "Chart name to clean by --constraint")
+	cleanup.Flags().StringVar(&constraint, "constraint", "", "SemVer constraint (e.g. \"<0.2.0\") matched against --chart's versions")
+	cleanup.Flags().IntVar(&pruneKeep, "prune-keep", 0, "Keep only the newest N semver versions of every chart in the index")
+	cleanup.Flags().BoolVar(&dryRun, "dry-run", false, "Print the cleanup plan without deleting or committing anything")
+	cleanup.Flags().BoolVar(&force, "force", false, "Skip digest verification against the on-disk chart archive before deleting an index entry")
+	cleanup.Flags().IntVar(&concurrency, "concurrency", 4, "Maximum number of chart releases to delete in parallel")
+	cleanup.Flags().StringArrayVar(&registries, "registry", nil, "Additional OCI registry (oci://host/repository) to also delete changed chart releases from; repeatable")
+	cleanup.Flags().StringVar(&keyringPath, "keyring", "", "GPG keyring to verify each release's .tgz.prov signature and digest against before deleting anything")
+	cleanup.Flags().StringVar(&labelSelector, "label-selector", "", "Restrict deletion to changed charts whose index entry matches this label selector (e.g. \"channel=nightly\")")
 	cleanup.MarkFlagRequired("owner")
 	cleanup.MarkFlagRequired("repo")
 
 	rootCmd.AddCommand(cleanup)
 
+	var labels = &cobra.Command{
+		Use:   "labels",
+		Short: "Manage labels (annotations) on chart index entries",
+	}
+	labels.PersistentFlags().StringVarP(&owner, "owner", "o", "", "GitHub repository owner")
+	labels.PersistentFlags().StringVarP(&repository, "repo", "r", "", "GitHub repository")
+	labels.PersistentFlags().StringVarP(&chartDir, "chart-dir", "d", "charts", "Directory containing Helm charts")
+	labels.PersistentFlags().StringVarP(&pagesBranch, "pages-branch", "p", "gh-pages", "Branch containing the chart index")
+	labels.PersistentFlags().StringVar(&backend, "backend", "", "Index backend URL (e.g. oci://ghcr.io/org/charts or git+https://...#gh-pages); defaults to --pages-branch")
+	labels.MarkPersistentFlagRequired("owner")
+	labels.MarkPersistentFlagRequired("repo")
+
+	var labelsSet = &cobra.Command{
+		Use:   "set",
+		Short: "Set labels on a chart index entry",
+		RunE:  setChartLabels,
+	}
+	labelsSet.Flags().StringVar(&labelChart, "chart", "", "Chart name")
+	labelsSet.Flags().StringVar(&labelVersion, "version", "", "Chart version")
+	labelsSet.Flags().StringArrayVar(&labelPairs, "label", nil, "Label to set, as key=value; repeatable")
+	labelsSet.MarkFlagRequired("chart")
+	labelsSet.MarkFlagRequired("version")
+
+	var labelsRemove = &cobra.Command{
+		Use:   "remove",
+		Short: "Remove labels from a chart index entry",
+		RunE:  removeChartLabels,
+	}
+	labelsRemove.Flags().StringVar(&labelChart, "chart", "", "Chart name")
+	labelsRemove.Flags().StringVar(&labelVersion, "version", "", "Chart version")
+	labelsRemove.Flags().StringArrayVar(&labelKeys, "key", nil, "Label key to remove; repeatable (omit to clear every label)")
+	labelsRemove.MarkFlagRequired("chart")
+	labelsRemove.MarkFlagRequired("version")
+
+	var labelsList = &cobra.Command{
+		Use:   "list",
+		Short: "List chart index entries matching a label selector",
+		RunE:  listChartLabels,
+	}
+	labelsList.Flags().StringVar(&labelSelector, "selector", "", "Kubernetes-style label selector (e.g. \"channel in (stable,beta)\")")
+	labelsList.MarkFlagRequired("selector")
+
+	labels.AddCommand(labelsSet, labelsRemove, labelsList)
+	rootCmd.AddCommand(labels)
+
 	if err := rootCmd.Execute(); err != nil {
 		if logger.Enabled() {
 			logger.Error(err, "Command execution failed")
@@ -77,54 +153,207 @@ Provides functionality to:
 	}
 }
 
-func cleanupChartIndex(cmd *cobra.Command, args []string) error {
-	cmdLogger := logger.WithName("cleanup")
-
+// newConfiguration reads the GITHUB_TOKEN environment variable and builds
+// an action.Configuration from the global owner/repository/chartDir/backend
+// flags, shared by cleanupChartIndex and the `labels` subcommands.
+func newConfiguration() (*action.Configuration, error) {
 	if owner == "" || repository == "" {
-		return fmt.Errorf("repository owner and name must be specified")
+		return nil, fmt.Errorf("repository owner and name must be specified")
+	}
+
+	ghToken := os.Getenv("GITHUB_TOKEN")
+	if ghToken == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN environment variable is not set")
+	}
+
+	indexBackend := backend
+	if indexBackend == "" {
+		indexBackend = pagesBranch
+	}
+
+	cfg := &action.Configuration{}
+	if err := cfg.Init(chartDir, indexBackend, owner, repository, ghToken); err != nil {
+		return nil, fmt.Errorf("failed to initialize configuration: %w", err)
 	}
+	return cfg, nil
+}
+
+func cleanupChartIndex(cmd *cobra.Command, args []string) error {
+	cmdLogger := logger.WithName("cleanup")
 
 	cmdLogger.Info("Starting cleanup process",
 		"owner", owner,
 		"repository", repository,
 		"chartDir", chartDir,
 		"pagesBranch", pagesBranch,
+		"backend", backend,
 		"baseBranch", baseBranch,
 		"versionPattern", versionPattern,
-		"cleanAllIndex", cleanAllIndex)
+		"cleanAllIndex", cleanAllIndex,
+		"match", match,
+		"keepLast", keepLast,
+		"chart", constraintFor,
+		"constraint", constraint,
+		"pruneKeep", pruneKeep,
+		"dryRun", dryRun,
+		"force", force,
+		"concurrency", concurrency,
+		"registries", registries,
+		"keyring", keyringPath,
+		"labelSelector", labelSelector)
 
-	// get ghtoken from environment variable
-	ghToken := os.Getenv("GITHUB_TOKEN")
-	if ghToken == "" {
-		return fmt.Errorf("GITHUB_TOKEN environment variable is not set")
-	}
-
-	git := internal.NewGit(chartDir)
-	ghc, err := internal.NewGHClient(owner, repository, ghToken)
+	cfg, err := newConfiguration()
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return err
 	}
-	index := internal.NewIndexManager(git, pagesBranch)
-	releaseManager := internal.NewReleaseManager(baseBranch, chartDir, git, index, ghc, versionPattern)
 
 	if cleanAllIndex {
 		cmdLogger.Info("Cleaning all chart index entries...")
-		if err := index.CleanAllEntries(); err != nil {
+		cleanIndex := action.NewCleanIndex(cfg)
+		cleanIndex.DryRun = dryRun
+		plan, err := cleanIndex.Run()
+		if err != nil {
 			return fmt.Errorf("failed to clean all chart index entries: %w", err)
 		}
+		if dryRun {
+			return plan.Print(os.Stdout, "yaml")
+		}
 		cmdLogger.Info("Successfully cleaned all chart index entries")
 		return nil
 	}
 
+	if constraintFor != "" || constraint != "" {
+		if constraintFor == "" || constraint == "" {
+			return fmt.Errorf("--chart and --constraint must be set together")
+		}
+		cmdLogger.Info("Cleaning chart index entries matching --constraint...")
+		cleanByConstraint := action.NewCleanByConstraint(cfg, constraintFor, constraint)
+		cleanByConstraint.DryRun = dryRun
+		cleanByConstraint.Force = force
+		plan, err := cleanByConstraint.Run()
+		if err != nil {
+			return fmt.Errorf("failed to clean chart index entries by constraint: %w", err)
+		}
+		if dryRun {
+			return plan.Print(os.Stdout, "yaml")
+		}
+		cmdLogger.Info("Successfully cleaned chart index entries by constraint")
+		return nil
+	}
+
+	if pruneKeep > 0 {
+		cmdLogger.Info("Pruning old chart index entries...")
+		pruneVersions := action.NewPruneVersions(cfg, pruneKeep)
+		pruneVersions.DryRun = dryRun
+		pruneVersions.Force = force
+		plan, err := pruneVersions.Run()
+		if err != nil {
+			return fmt.Errorf("failed to prune old chart index entries: %w", err)
+		}
+		if dryRun {
+			return plan.Print(os.Stdout, "yaml")
+		}
+		cmdLogger.Info("Successfully pruned old chart index entries")
+		return nil
+	}
+
+	if len(match) > 0 {
+		cmdLogger.Info("Cleaning chart index entries matching --match...")
+		cleanMatching := action.NewCleanMatchingVersions(cfg, match, keepLast)
+		cleanMatching.DryRun = dryRun
+		cleanMatching.Force = force
+		plan, err := cleanMatching.Run()
+		if err != nil {
+			return fmt.Errorf("failed to clean matching chart index entries: %w", err)
+		}
+		if dryRun {
+			return plan.Print(os.Stdout, "yaml")
+		}
+		cmdLogger.Info("Successfully cleaned matching chart index entries")
+		return nil
+	}
+
 	cmdLogger.Info("Deleting changed chart releases...")
-	if err := releaseManager.DeleteChangedCharts(); err != nil {
+	deleteChangedCharts := action.NewDeleteChangedCharts(cfg, baseBranch, chartDir, versionPattern)
+	deleteChangedCharts.DryRun = dryRun
+	deleteChangedCharts.Concurrency = concurrency
+	deleteChangedCharts.Force = force
+	deleteChangedCharts.Registries = registries
+	deleteChangedCharts.KeyringPath = keyringPath
+	deleteChangedCharts.LabelSelector = labelSelector
+	plan, err := deleteChangedCharts.Run()
+	if err != nil {
 		return fmt.Errorf("failed to delete changed chart releases: %w", err)
 	}
+	if dryRun {
+		return plan.Print(os.Stdout, "yaml")
+	}
 	cmdLogger.Info("Successfully deleted changed chart releases")
 
 	return nil
 }
 
+// parseLabelPairs parses "key=value" flag values into a label map.
+func parseLabelPairs(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --label %q: expected key=value", pair)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+func setChartLabels(cmd *cobra.Command, args []string) error {
+	cfg, err := newConfiguration()
+	if err != nil {
+		return err
+	}
+
+	newLabels, err := parseLabelPairs(labelPairs)
+	if err != nil {
+		return err
+	}
+
+	if err := action.NewSetLabels(cfg, labelChart, labelVersion, newLabels).Run(); err != nil {
+		return err
+	}
+	logger.WithName("labels").Info("Successfully set labels", "chart", labelChart, "version", labelVersion, "labels", newLabels)
+	return nil
+}
+
+func removeChartLabels(cmd *cobra.Command, args []string) error {
+	cfg, err := newConfiguration()
+	if err != nil {
+		return err
+	}
+
+	if err := action.NewRemoveLabels(cfg, labelChart, labelVersion, labelKeys).Run(); err != nil {
+		return err
+	}
+	logger.WithName("labels").Info("Successfully removed labels", "chart", labelChart, "version", labelVersion, "keys", labelKeys)
+	return nil
+}
+
+func listChartLabels(cmd *cobra.Command, args []string) error {
+	cfg, err := newConfiguration()
+	if err != nil {
+		return err
+	}
+
+	matched, err := action.NewListLabels(cfg, labelSelector).Run()
+	if err != nil {
+		return err
+	}
+
+	for _, chart := range matched {
+		fmt.Printf("%s\t%s\n", chart.Name, chart.Version)
+	}
+	return nil
+}
+
 func init() {
 	viper.AutomaticEnv()
 }
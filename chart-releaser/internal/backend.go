@@ -0,0 +1,371 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// Backend abstracts where chart index entries physically live, so
+// IndexManager can manage a gh-pages index.yaml or an OCI registry
+// repository through the same CleanEntriesVersions/CleanAllEntries calls.
+type Backend interface {
+	// Load fetches the current set of indexed chart versions.
+	Load() (*repo.IndexFile, error)
+	// RemoveVersion removes a single chart version.
+	RemoveVersion(name, version string) error
+	// RemoveAll removes every chart entry.
+	RemoveAll() error
+	// Commit persists pending changes. Backends that write immediately
+	// (such as OCI) may treat this as a no-op.
+	Commit(msg string) error
+}
+
+// DigestVerifier is implemented by backends that can check an index
+// entry's recorded digest against the chart archive it describes, so
+// IndexManager can refuse to delete a version whose index entry disagrees
+// with the artifact on disk. Only GitPagesBackend implements it; OCI
+// registries have no separate on-disk artifact to check against.
+type DigestVerifier interface {
+	// VerifyDigest reports whether the SHA-256 digest of "<name>-<version>.tgz"
+	// matches digest (the index entry's "digest" field). A missing tarball
+	// or an empty digest verifies successfully, since neither is evidence
+	// of corruption on its own.
+	VerifyDigest(name, version, digest string) (bool, error)
+}
+
+// GitPagesBackend manages index.yaml on a gh-pages-style branch. This is
+// the original, and still default, IndexManager behavior. It reads and
+// mutates the branch through an in-memory clone (see Git.cloneBranchInMemory)
+// rather than checking it out in the caller's real working directory, so
+// it never touches the caller's current branch or HEAD and works even
+// from a dirty working tree.
+type GitPagesBackend struct {
+	Git         *Git
+	PagesBranch string
+
+	memRepo      *gogit.Repository
+	wt           Worktree
+	indexFile    *repo.IndexFile
+	provenance   *ProvenanceCleaner
+	removedFiles []string
+	logger       logr.Logger
+}
+
+// NewGitPagesBackend creates a Backend backed by a git branch holding
+// index.yaml.
+func NewGitPagesBackend(git *Git, pagesBranch string) *GitPagesBackend {
+	return &GitPagesBackend{
+		Git:         git,
+		PagesBranch: pagesBranch,
+		provenance:  NewProvenanceCleaner(),
+		logger:      Logger.WithName("git-pages-backend"),
+	}
+}
+
+// Load clones the pages branch into memory and loads index.yaml. Because
+// the clone never touches the caller's real working directory or HEAD,
+// Load is always safe to call on its own, without a matching Commit
+// (e.g. IndexManager.LoadIndex), and from a dirty working tree.
+func (b *GitPagesBackend) Load() (*repo.IndexFile, error) {
+	if !b.Git.BranchExists(b.PagesBranch) {
+		b.logger.Info("Pages branch does not exist, skipping index cleanup", "branch", b.PagesBranch)
+		return nil, fmt.Errorf("pages branch does not exist")
+	}
+
+	memRepo, wt, err := b.Git.cloneBranchInMemory(b.PagesBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s branch: %w", b.PagesBranch, err)
+	}
+
+	exists, err := wt.Exists(indexYaml)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat index.yaml: %w", err)
+	}
+
+	var indexFile *repo.IndexFile
+	if !exists {
+		indexFile = repo.NewIndexFile()
+	} else {
+		data, err := wt.ReadFile(indexYaml)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index.yaml: %w", err)
+		}
+		indexFile, err = loadIndexFileBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load index.yaml: %w", err)
+		}
+	}
+
+	b.memRepo = memRepo
+	b.wt = wt
+	b.indexFile = indexFile
+	return indexFile, nil
+}
+
+// loadIndexFileBytes parses index.yaml the same way repo.LoadIndexFile
+// does, for callers (like GitPagesBackend.Load) that only have the raw
+// bytes of an in-memory clone rather than a real file path.
+func loadIndexFileBytes(data []byte) (*repo.IndexFile, error) {
+	tmp, err := os.CreateTemp("", "index-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	return repo.LoadIndexFile(tmp.Name())
+}
+
+// RemoveVersion removes a single chart version from the loaded index.
+func (b *GitPagesBackend) RemoveVersion(name, version string) error {
+	if b.indexFile == nil {
+		return fmt.Errorf("index not loaded")
+	}
+
+	existVersions, exists := b.indexFile.Entries[name]
+	if !exists {
+		b.logger.Info("No entries found for chart", "chart", name)
+		return nil
+	}
+
+	filteredVersions := make(repo.ChartVersions, 0, len(existVersions))
+	for _, v := range existVersions {
+		if v.Version != version {
+			filteredVersions = append(filteredVersions, v)
+		}
+	}
+
+	if len(filteredVersions) == 0 {
+		delete(b.indexFile.Entries, name)
+	} else {
+		b.indexFile.Entries[name] = filteredVersions
+	}
+
+	removed, err := b.provenance.Clean(b.wt, &ChartInfo{Name: name, Version: version})
+	if err != nil {
+		return fmt.Errorf("failed to clean provenance sidecars for %s-%s: %w", name, version, err)
+	}
+	b.removedFiles = append(b.removedFiles, removed...)
+
+	return nil
+}
+
+// VerifyDigest recomputes the SHA-256 digest of "<name>-<version>.tgz" on
+// the pages branch and reports whether it matches digest.
+func (b *GitPagesBackend) VerifyDigest(name, version, digest string) (bool, error) {
+	if digest == "" {
+		return true, nil
+	}
+	if b.wt == nil {
+		return false, fmt.Errorf("index not loaded")
+	}
+
+	path := fmt.Sprintf("%s-%s.tgz", name, version)
+	exists, err := b.wt.Exists(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if !exists {
+		return true, nil
+	}
+
+	data, err := b.wt.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) == digest, nil
+}
+
+// RemoveAll clears every entry from the loaded index.
+func (b *GitPagesBackend) RemoveAll() error {
+	if b.indexFile == nil {
+		return fmt.Errorf("index not loaded")
+	}
+
+	for name, versions := range b.indexFile.Entries {
+		for _, v := range versions {
+			removed, err := b.provenance.Clean(b.wt, &ChartInfo{Name: name, Version: v.Version})
+			if err != nil {
+				return fmt.Errorf("failed to clean provenance sidecars for %s-%s: %w", name, v.Version, err)
+			}
+			b.removedFiles = append(b.removedFiles, removed...)
+		}
+	}
+
+	b.indexFile.Entries = make(map[string]repo.ChartVersions)
+	return nil
+}
+
+// Commit writes index.yaml back to the in-memory clone and, if anything
+// changed, commits and pushes it to the pages branch.
+func (b *GitPagesBackend) Commit(msg string) error {
+	if b.indexFile == nil {
+		return fmt.Errorf("index not loaded")
+	}
+
+	data, err := yaml.Marshal(b.indexFile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index.yaml: %w", err)
+	}
+	if err := b.wt.WriteFile(indexYaml, data); err != nil {
+		return fmt.Errorf("failed to write index.yaml: %w", err)
+	}
+	b.logger.Info("Successfully wrote index.yaml")
+
+	changedPaths := append([]string{indexYaml}, b.removedFiles...)
+	changed, err := b.wt.HasChanges(changedPaths...)
+	if err != nil {
+		return fmt.Errorf("failed to check for changes: %w", err)
+	}
+	if !changed {
+		b.logger.Info("No changes detected, skipping commit", "file", indexYaml)
+		return nil
+	}
+
+	if err := b.wt.Commit(msg, changedPaths...); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	if err := b.Git.pushBranch(b.memRepo); err != nil {
+		return fmt.Errorf("failed to push changes: %w", err)
+	}
+	b.logger.Info("Successfully committed and pushed changes", "branch", b.PagesBranch)
+	return nil
+}
+
+// chartTagPattern splits an OCI tag of the form "<chart>-<semver>" into its
+// chart name and version. OCI tags cannot contain a "+" (build metadata is
+// normalized to "_" by convention), but otherwise follow the same
+// "<chart>-<version>" shape as GitHub release tags.
+var chartTagPattern = regexp.MustCompile(`^(.+)-(\d+\.\d+\.\d+.*)$`)
+
+func splitChartTag(tag string) (name, version string, ok bool) {
+	m := chartTagPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// OCIBackend manages chart entries pushed to an OCI-compliant registry
+// (ghcr.io, ECR, Harbor) instead of a gh-pages index.yaml. Chart versions
+// are addressed by the "<chart>-<version>" tag convention used by `helm
+// push`.
+type OCIBackend struct {
+	// Repository is the repository reference including its registry host,
+	// e.g. "ghcr.io/zncdatadev/charts".
+	Repository string
+
+	client *ociRegistryClient
+	logger logr.Logger
+}
+
+// NewOCIBackend creates a Backend backed by an OCI registry repository.
+func NewOCIBackend(repository string) *OCIBackend {
+	return &OCIBackend{
+		Repository: repository,
+		logger:     Logger.WithName("oci-backend"),
+	}
+}
+
+// Load lists every chart tag in the repository and synthesizes an
+// repo.IndexFile entry per chart/version, since OCI registries have no
+// native index.yaml.
+func (b *OCIBackend) Load() (*repo.IndexFile, error) {
+	client, err := b.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := client.ListTags(b.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags for %s: %w", b.Repository, err)
+	}
+
+	indexFile := repo.NewIndexFile()
+	for _, tag := range tags {
+		name, version, ok := splitChartTag(tag)
+		if !ok {
+			continue
+		}
+		indexFile.Entries[name] = append(indexFile.Entries[name], &repo.ChartVersion{
+			Metadata: &chart.Metadata{Name: name, Version: version},
+		})
+	}
+
+	return indexFile, nil
+}
+
+// RemoveVersion deletes the manifest tagged "<name>-<version>".
+func (b *OCIBackend) RemoveVersion(name, version string) error {
+	client, err := b.connect()
+	if err != nil {
+		return err
+	}
+
+	tag := fmt.Sprintf("%s-%s", name, version)
+	if err := client.DeleteTag(b.Repository, tag); err != nil {
+		return fmt.Errorf("failed to delete %s from %s: %w", tag, b.Repository, err)
+	}
+	b.logger.Info("Deleted OCI manifest", "repository", b.Repository, "tag", tag)
+	return nil
+}
+
+// RemoveAll deletes every chart manifest in the repository.
+func (b *OCIBackend) RemoveAll() error {
+	client, err := b.connect()
+	if err != nil {
+		return err
+	}
+
+	tags, err := client.ListTags(b.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to list tags for %s: %w", b.Repository, err)
+	}
+
+	for _, tag := range tags {
+		if _, _, ok := splitChartTag(tag); !ok {
+			continue
+		}
+		if err := client.DeleteTag(b.Repository, tag); err != nil {
+			return fmt.Errorf("failed to delete %s from %s: %w", tag, b.Repository, err)
+		}
+	}
+
+	return nil
+}
+
+// Commit is a no-op: OCI manifest deletes take effect immediately, so
+// there is nothing left to push.
+func (b *OCIBackend) Commit(msg string) error {
+	return nil
+}
+
+func (b *OCIBackend) connect() (*ociRegistryClient, error) {
+	if b.client != nil {
+		return b.client, nil
+	}
+	client, err := newOCIRegistryClient(b.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to OCI repository %s: %w", b.Repository, err)
+	}
+	b.client = client
+	return client, nil
+}
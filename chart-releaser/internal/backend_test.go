@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// chdir changes the test process's working directory to dir and restores
+// it when t ends. testing.T.Chdir only exists from Go 1.24, and nothing
+// in this repo pins a minimum Go version, so tests use this instead.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to chdir to %s: %v", dir, err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(old); err != nil {
+			t.Fatalf("failed to restore working directory: %v", err)
+		}
+	})
+}
+
+// setupGitPagesRepo creates a bare remote with a "main" branch and a
+// "gh-pages" branch holding index.yaml, and a local clone checked out on
+// "main", returning the local working directory.
+func setupGitPagesRepo(t *testing.T) string {
+	t.Helper()
+
+	base := t.TempDir()
+	remoteDir := filepath.Join(base, "remote.git")
+	workDir := filepath.Join(base, "work")
+
+	if _, err := gogit.PlainInit(remoteDir, true); err != nil {
+		t.Fatalf("failed to init bare remote: %v", err)
+	}
+
+	workRepo, err := gogit.PlainInit(workDir, false)
+	if err != nil {
+		t.Fatalf("failed to init work repo: %v", err)
+	}
+	wt, err := workRepo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(workDir, "README.md"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+	if _, err := wt.Add("README.md"); err != nil {
+		t.Fatalf("failed to add README.md: %v", err)
+	}
+	if _, err := wt.Commit("seed commit", &gogit.CommitOptions{Author: commitSignature}); err != nil {
+		t.Fatalf("failed to create seed commit: %v", err)
+	}
+
+	head, err := workRepo.Head()
+	if err != nil {
+		t.Fatalf("failed to resolve HEAD: %v", err)
+	}
+	if head.Name().Short() != "main" {
+		if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main"), Create: true}); err != nil {
+			t.Fatalf("failed to create main branch: %v", err)
+		}
+	}
+
+	if _, err := workRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("failed to add origin remote: %v", err)
+	}
+	pushBranch := func(branch string) {
+		refSpec := config.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch)
+		if err := workRepo.Push(&gogit.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}}); err != nil {
+			t.Fatalf("failed to push %s: %v", branch, err)
+		}
+	}
+	pushBranch("main")
+
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("gh-pages"), Create: true}); err != nil {
+		t.Fatalf("failed to create gh-pages branch: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, indexYaml), []byte("apiVersion: v1\nentries: {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write index.yaml: %v", err)
+	}
+	if _, err := wt.Add(indexYaml); err != nil {
+		t.Fatalf("failed to add index.yaml: %v", err)
+	}
+	if _, err := wt.Commit("add index.yaml", &gogit.CommitOptions{Author: commitSignature}); err != nil {
+		t.Fatalf("failed to commit index.yaml: %v", err)
+	}
+	pushBranch("gh-pages")
+
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")}); err != nil {
+		t.Fatalf("failed to checkout main: %v", err)
+	}
+
+	return workDir
+}
+
+func TestGitPagesBackendLoadThenNoCommitStillRestoresBranch(t *testing.T) {
+	workDir := setupGitPagesRepo(t)
+	chdir(t, workDir)
+
+	git := NewGit(workDir)
+	backend := NewGitPagesBackend(git, "gh-pages")
+
+	if _, err := backend.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("expected Load without a matching Commit to restore the original branch, got %q", branch)
+	}
+}
+
+func TestGitPagesBackendLoadTwiceRestoresToSameOriginalBranch(t *testing.T) {
+	workDir := setupGitPagesRepo(t)
+	chdir(t, workDir)
+
+	git := NewGit(workDir)
+	backend := NewGitPagesBackend(git, "gh-pages")
+
+	if _, err := backend.Load(); err != nil {
+		t.Fatalf("first Load failed: %v", err)
+	}
+	if _, err := backend.Load(); err != nil {
+		t.Fatalf("second Load failed: %v", err)
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("expected repeated Load calls to keep restoring to %q, got %q", "main", branch)
+	}
+}
+
+func TestGitPagesBackendCommitRestoresOriginalBranch(t *testing.T) {
+	workDir := setupGitPagesRepo(t)
+	chdir(t, workDir)
+
+	git := NewGit(workDir)
+	backend := NewGitPagesBackend(git, "gh-pages")
+
+	if _, err := backend.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if err := backend.RemoveAll(); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if err := backend.Commit("clean index"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("expected Commit to restore the original branch, got %q", branch)
+	}
+}
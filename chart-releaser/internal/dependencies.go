@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChartDependency mirrors the fields of a Chart.yaml (or legacy
+// requirements.yaml) dependency entry that ResolveDependencyGraph needs,
+// matching what Helm's downloader.Manager resolves.
+type ChartDependency struct {
+	Name       string   `yaml:"name"`
+	Version    string   `yaml:"version"`
+	Repository string   `yaml:"repository"`
+	Condition  string   `yaml:"condition"`
+	Tags       []string `yaml:"tags"`
+}
+
+// requirementsFile is the legacy (apiVersion v1) equivalent of Chart.yaml's
+// "dependencies:" block.
+type requirementsFile struct {
+	Dependencies []ChartDependency `yaml:"dependencies"`
+}
+
+// loadDependencies returns a chart's dependency list, falling back to
+// requirements.yaml for apiVersion v1 charts, which don't carry
+// "dependencies:" in Chart.yaml itself.
+func loadDependencies(chartPath, apiVersion string, dependencies []ChartDependency) ([]ChartDependency, error) {
+	if apiVersion != "v1" {
+		return dependencies, nil
+	}
+
+	reqFile := filepath.Join(chartPath, "requirements.yaml")
+	data, err := os.ReadFile(reqFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", reqFile, err)
+	}
+
+	var req requirementsFile
+	if err := yaml.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", reqFile, err)
+	}
+	return req.Dependencies, nil
+}
+
+// isDependencyEnabled evaluates dep's "condition" and "tags" against
+// parentPath's values.yaml, mirroring Helm's subchart enable/disable
+// rules: a condition path (or the first of a comma-separated list of
+// them) that resolves to a bool wins outright; otherwise the dependency
+// is enabled if any of its tags is set true in the "tags:" section. A
+// dependency with neither condition nor tags is always enabled.
+func isDependencyEnabled(parentPath string, dep ChartDependency) bool {
+	if dep.Condition == "" && len(dep.Tags) == 0 {
+		return true
+	}
+
+	values, err := loadValues(parentPath)
+	if err != nil {
+		return true
+	}
+
+	if dep.Condition != "" {
+		for _, path := range strings.Split(dep.Condition, ",") {
+			if v, ok := lookupValuePath(values, strings.TrimSpace(path)); ok {
+				if enabled, ok := v.(bool); ok {
+					return enabled
+				}
+			}
+		}
+	}
+
+	if len(dep.Tags) > 0 {
+		tags, _ := values["tags"].(map[string]interface{})
+		for _, tag := range dep.Tags {
+			if enabled, ok := tags[tag].(bool); ok && enabled {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// loadValues reads and parses chartPath/values.yaml.
+func loadValues(chartPath string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(chartPath, "values.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// lookupValuePath resolves a dotted path (e.g. "subchart.enabled") against
+// a parsed values.yaml tree.
+func lookupValuePath(values map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = values
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// ResolveDependencyGraph scans every Helm chart under m.chartDir and
+// returns a map from chart name to the names of its enabled local
+// ("file://") dependencies, resolved to sibling chart directories.
+// Dependencies on remote repositories are ignored, since only local
+// charts can affect change detection. It returns an error naming the
+// cycle if the local dependencies don't form a DAG.
+func (m *ReleaseManager) ResolveDependencyGraph() (map[string][]string, error) {
+	charts, err := m.listAllCharts()
+	if err != nil {
+		return nil, err
+	}
+
+	chartsByPath := make(map[string]*ChartInfo, len(charts))
+	for _, chart := range charts {
+		chartsByPath[filepath.Clean(chart.Path)] = chart
+	}
+
+	graph := make(map[string][]string, len(charts))
+	for _, chart := range charts {
+		data, err := os.ReadFile(filepath.Join(chart.Path, "Chart.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart file for %s: %w", chart.Name, err)
+		}
+
+		var metadata ChartMetadata
+		if err := yaml.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse chart file for %s: %w", chart.Name, err)
+		}
+
+		dependencies, err := loadDependencies(chart.Path, metadata.APIVersion, metadata.Dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dependencies for %s: %w", chart.Name, err)
+		}
+
+		var localDeps []string
+		for _, dep := range dependencies {
+			if !strings.HasPrefix(dep.Repository, "file://") {
+				continue
+			}
+			if !isDependencyEnabled(chart.Path, dep) {
+				m.logger.Info("Dependency disabled by its condition/tags, skipping", "dependency", dep.Name, "chart", chart.Name)
+				continue
+			}
+
+			depPath := filepath.Clean(filepath.Join(chart.Path, strings.TrimPrefix(dep.Repository, "file://")))
+			depChart, ok := chartsByPath[depPath]
+			if !ok {
+				return nil, fmt.Errorf("chart %s depends on %s, but no chart was found at %s", chart.Name, dep.Repository, depPath)
+			}
+			localDeps = append(localDeps, depChart.Name)
+		}
+
+		sort.Strings(localDeps)
+		graph[chart.Name] = localDeps
+	}
+
+	names := make([]string, 0, len(graph))
+	for name := range graph {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if _, err := topologicalOrder(names, graph); err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// sortedKeys returns the keys of a bool set in sorted order, for
+// deterministic traversal order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// topologicalOrder returns names sorted so that every chart appears before
+// all the local dependencies (per graph) it transitively requires — i.e.
+// the order releases should be deleted in, parents before children, so a
+// dependency is never deleted while a chart that still locally depends on
+// it remains. It returns an error naming the cycle if graph restricted to
+// names isn't a DAG.
+func topologicalOrder(names []string, graph map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var order []string
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("cyclic chart dependency detected: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range graph[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	// visit appends each name in dependencies-first (post-order) order;
+	// reverse it so the result is parents-first instead.
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order, nil
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopologicalOrderOrdersParentsFirst(t *testing.T) {
+	graph := map[string][]string{
+		"app":    {"lib", "util"},
+		"lib":    {"util"},
+		"util":   nil,
+		"orphan": nil,
+	}
+	names := []string{"app", "lib", "orphan", "util"}
+
+	order, err := topologicalOrder(names, graph)
+	if err != nil {
+		t.Fatalf("topologicalOrder failed: %v", err)
+	}
+
+	pos := make(map[string]int, len(order))
+	for i, name := range order {
+		pos[name] = i
+	}
+	if pos["app"] >= pos["lib"] {
+		t.Errorf("expected app before lib, got order %v", order)
+	}
+	if pos["lib"] >= pos["util"] {
+		t.Errorf("expected lib before util, got order %v", order)
+	}
+	if pos["app"] >= pos["util"] {
+		t.Errorf("expected app before util, got order %v", order)
+	}
+}
+
+func TestTopologicalOrderDetectsCycle(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	}
+
+	_, err := topologicalOrder([]string{"a", "b", "c"}, graph)
+	if err == nil {
+		t.Fatal("expected an error for a cyclic dependency graph, got nil")
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("expected the error to name the cycle, got: %v", err)
+	}
+}
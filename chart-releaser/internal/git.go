@@ -2,56 +2,95 @@ package internal
 
 import (
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"strings"
 
+	"github.com/go-git/go-billy/v5/memfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/go-logr/logr"
 )
 
+// commitSignature is the identity go-git commits are authored as. Unlike
+// an exec.Command("git", "commit"), go-git commits in-process and never
+// consults the system or global git config, so without an explicit
+// signature every commit fails with "author field is required".
+var commitSignature = &object.Signature{
+	Name:  "chart-release-manager",
+	Email: "chart-release-manager@users.noreply.github.com",
+}
+
 // Git manages Git operations for detecting changes and managing branches
+// against the repository checked out at repoPath, implemented on top of
+// go-git instead of shelling out to the git binary.
 type Git struct {
-	logger logr.Logger
+	repoPath string
+	repo     *gogit.Repository
+	logger   logr.Logger
 }
 
-// NewGit creates a new Git instance
+// NewGit creates a new Git instance rooted at repoPath.
 func NewGit(repoPath string) *Git {
 	return &Git{
-		logger: Logger.WithName("git"),
+		repoPath: repoPath,
+		logger:   Logger.WithName("git"),
 	}
 }
 
-// runGitCommand runs a git command and returns its output
-func (g *Git) runGitCommand(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-
-	g.logger.V(1).Info("Running git command", "args", args)
+// open lazily opens the on-disk repository at g.repoPath.
+func (g *Git) open() (*gogit.Repository, error) {
+	if g.repo != nil {
+		return g.repo, nil
+	}
 
-	output, err := cmd.Output()
+	repo, err := gogit.PlainOpen(g.repoPath)
 	if err != nil {
-		return "", fmt.Errorf("git command failed. args: %v, error: %w", args, err)
+		return nil, fmt.Errorf("failed to open repository at %s: %w", g.repoPath, err)
 	}
-
-	result := strings.TrimSpace(string(output))
-	g.logger.V(2).Info("Git command output", "args", args, "output", result)
-	return result, nil
+	g.repo = repo
+	return repo, nil
 }
 
 // GetCurrentBranch gets the current branch name
 func (g *Git) GetCurrentBranch() (string, error) {
-	return g.runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
+	repo, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return head.Name().Short(), nil
 }
 
-// FetchTags fetches tags from remote repository
+// fetchTags fetches tags from the "origin" remote.
 func (g *Git) fetchTags() error {
 	g.logger.Info("Fetching tags from remote")
-	_, err := g.runGitCommand("fetch", "--tags")
+
+	repo, err := g.open()
 	if err != nil {
+		return err
+	}
+
+	err = repo.Fetch(&gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+	})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
 		g.logger.Error(err, "Failed to fetch tags from remote")
+		return err
 	}
-	return err
+	return nil
 }
 
-// GetLatestTag gets the latest tag or appropriate commit for comparison
+// getLatestTag gets the latest tag or appropriate commit for comparison
 func (g *Git) getLatestTag(baseBranch string) (string, error) {
 	// Fetch tags first
 	g.fetchTags()
@@ -63,9 +102,9 @@ func (g *Git) getLatestTag(baseBranch string) (string, error) {
 
 	g.logger.Info("Getting latest tag", "currentBranch", currentBranch, "baseBranch", baseBranch)
 
-	// Try to get the latest tag
-	latestTag, err := g.runGitCommand("describe", "--tags", "--abbrev=0", "HEAD~")
-	if err == nil {
+	// Try to find the tag nearest to HEAD~, mirroring
+	// `git describe --tags --abbrev=0 HEAD~`.
+	if latestTag, err := g.nearestTagBeforeHead(); err == nil {
 		g.logger.Info("Found latest tag", "tag", latestTag)
 		return latestTag, nil
 	}
@@ -76,15 +115,166 @@ func (g *Git) getLatestTag(baseBranch string) (string, error) {
 	if currentBranch == baseBranch {
 		// On base branch, use first commit
 		g.logger.Info("On base branch, using first commit")
-		return g.runGitCommand("rev-list", "--max-parents=0", "--first-parent", "HEAD")
-	} else {
-		// On other branches, use merge base with base branch
-		g.logger.Info("On feature branch, using merge base with base branch")
-		return g.runGitCommand("merge-base", "HEAD", baseBranch)
+		return g.firstCommit()
+	}
+
+	// On other branches, use merge base with base branch
+	g.logger.Info("On feature branch, using merge base with base branch")
+	return g.mergeBase(baseBranch)
+}
+
+// nearestTagBeforeHead walks the history reachable from HEAD~ (following
+// every parent, not just the first), and returns the name of the first tag it finds pointing at a
+// visited commit.
+func (g *Git) nearestTagBeforeHead() (string, error) {
+	repo, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD commit: %w", err)
+	}
+
+	parent, err := headCommit.Parents().Next()
+	if err != nil {
+		return "", fmt.Errorf("HEAD has no parent commit: %w", err)
+	}
+
+	tagsByHash := make(map[plumbing.Hash]string)
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	if err := tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tagObj, err := repo.TagObject(hash); err == nil {
+			hash = tagObj.Target
+		}
+		tagsByHash[hash] = ref.Name().Short()
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to resolve tags: %w", err)
+	}
+	if len(tagsByHash) == 0 {
+		return "", fmt.Errorf("no tags found")
+	}
+
+	commitIter, err := repo.Log(&gogit.LogOptions{From: parent.Hash})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk commit history: %w", err)
+	}
+
+	var found string
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if name, ok := tagsByHash[c.Hash]; ok {
+			found = name
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("no reachable tag found")
+	}
+	return found, nil
+}
+
+// firstCommit returns the hash of the repository's first (root) commit
+// reachable from HEAD.
+func (g *Git) firstCommit() (string, error) {
+	repo, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	commitIter, err := repo.Log(&gogit.LogOptions{From: head.Hash()})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk commit history: %w", err)
+	}
+
+	var last *object.Commit
+	if err := commitIter.ForEach(func(c *object.Commit) error {
+		last = c
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if last == nil {
+		return "", fmt.Errorf("no commits found")
+	}
+	return last.Hash.String(), nil
+}
+
+// mergeBase returns the merge base of HEAD and the "origin/<baseBranch>"
+// remote-tracking branch.
+func (g *Git) mergeBase(baseBranch string) (string, error) {
+	repo, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD commit: %w", err)
+	}
+
+	baseRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", baseBranch), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve origin/%s: %w", baseBranch, err)
+	}
+	baseCommit, err := repo.CommitObject(baseRef.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s commit: %w", baseBranch, err)
+	}
+
+	bases, err := headCommit.MergeBase(baseCommit)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute merge base: %w", err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("no merge base found with %s", baseBranch)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// resolveCommit resolves rev (a commit hash or a tag name) to a commit object.
+func (g *Git) resolveCommit(repo *gogit.Repository, rev string) (*object.Commit, error) {
+	if hash := plumbing.NewHash(rev); !hash.IsZero() {
+		if commit, err := repo.CommitObject(hash); err == nil {
+			return commit, nil
+		}
 	}
+
+	ref, err := repo.Tag(rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", rev, err)
+	}
+	hash := ref.Hash()
+	if tagObj, err := repo.TagObject(hash); err == nil {
+		hash = tagObj.Target
+	}
+	return repo.CommitObject(hash)
 }
 
-// GetChangedFiles gets list of changed files since a commit
+// GetChangedFiles gets list of files under pathFilter changed since a commit
 func (g *Git) GetChangedFiles(baseBranch, pathFilter string) ([]string, error) {
 	sinceCommit, err := g.getLatestTag(baseBranch)
 	if err != nil {
@@ -96,75 +286,333 @@ func (g *Git) GetChangedFiles(baseBranch, pathFilter string) ([]string, error) {
 
 	g.logger.Info("Getting changed files", "sinceCommit", sinceCommit, "pathFilter", pathFilter)
 
-	args := []string{"diff", "--find-renames", "--name-only", sinceCommit}
-	if pathFilter != "" {
-		args = append(args, "--", pathFilter)
+	repo, err := g.open()
+	if err != nil {
+		return nil, err
 	}
 
-	output, err := g.runGitCommand(args...)
+	fromCommit, err := g.resolveCommit(repo, sinceCommit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get changed files: %w", err)
+		return nil, fmt.Errorf("failed to resolve %s: %w", sinceCommit, err)
 	}
 
-	if output == "" {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	toCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD commit: %w", err)
+	}
+
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree for %s: %w", sinceCommit, err)
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
+	}
+
+	changes, err := fromTree.Diff(toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	var files []string
+	for _, change := range changes {
+		path := change.To.Name
+		if path == "" {
+			path = change.From.Name
+		}
+		if pathFilter != "" && !strings.HasPrefix(path, pathFilter) {
+			continue
+		}
+		files = append(files, path)
+	}
+
+	if len(files) == 0 {
 		g.logger.Info("No changed files found")
 		return []string{}, nil
 	}
 
-	files := strings.Split(output, "\n")
 	g.logger.Info("Found changed files", "count", len(files), "files", files)
 	return files, nil
 }
 
-// CheckoutBranch checks out a branch
+// CheckoutBranch checks out a branch in the caller's working tree.
 func (g *Git) CheckoutBranch(branch string) error {
-	_, err := g.runGitCommand("checkout", branch)
-	return err
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch)}); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", branch, err)
+	}
+	return nil
 }
 
 // BranchExists checks if a branch exists on remote
 func (g *Git) BranchExists(branch string) bool {
-	output, err := g.runGitCommand("ls-remote", "--heads", "origin", branch)
+	repo, err := g.open()
 	if err != nil {
 		return false
 	}
-	return strings.Contains(output, branch)
+
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return false
+	}
+
+	refs, err := remote.List(&gogit.ListOptions{})
+	if err != nil {
+		return false
+	}
+
+	target := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == target {
+			return true
+		}
+	}
+	return false
 }
 
 // FetchBranch fetches a branch from remote
 func (g *Git) FetchBranch(branch string) error {
-	_, err := g.runGitCommand("fetch", "origin", branch)
-	return err
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch))
+	err = repo.Fetch(&gogit.FetchOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to fetch %s: %w", branch, err)
+	}
+	return nil
 }
 
 // PullBranch pulls latest changes from a branch
 func (g *Git) PullBranch(branch string) error {
-	_, err := g.runGitCommand("pull", "origin", branch)
-	return err
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	err = wt.Pull(&gogit.PullOptions{RemoteName: "origin", ReferenceName: plumbing.NewBranchReferenceName(branch)})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to pull %s: %w", branch, err)
+	}
+	return nil
 }
 
 // HasChanges checks if a file has uncommitted changes
 func (g *Git) HasChanges(filePath string) bool {
-	_, err := g.runGitCommand("diff", "--quiet", filePath)
-	return err != nil // Has changes if command fails
+	return g.HasAnyChanges(filePath)
+}
+
+// HasAnyChanges checks if any of the given files have uncommitted changes.
+func (g *Git) HasAnyChanges(filePaths ...string) bool {
+	repo, err := g.open()
+	if err != nil {
+		return false
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false
+	}
+
+	for _, filePath := range filePaths {
+		s, ok := status[filePath]
+		if ok && (s.Worktree != gogit.Unmodified || s.Staging != gogit.Unmodified) {
+			return true
+		}
+	}
+	return false
 }
 
 // CommitAndPush commits and pushes changes to a file
 func (g *Git) CommitAndPush(filePath, message, branch string) error {
-	// Add file
-	if _, err := g.runGitCommand("add", filePath); err != nil {
-		return fmt.Errorf("failed to add file: %w", err)
+	return g.CommitAndPushFiles([]string{filePath}, message, branch)
+}
+
+// CommitAndPushFiles commits and pushes changes across multiple files in a
+// single commit, for callers (like provenance cleanup) that mutate more
+// than just index.yaml in one pass.
+func (g *Git) CommitAndPushFiles(filePaths []string, message, branch string) error {
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	// Commit
-	if _, err := g.runGitCommand("commit", "-m", message); err != nil {
+	for _, filePath := range filePaths {
+		if _, err := wt.Add(filePath); err != nil {
+			return fmt.Errorf("failed to add file %s: %w", filePath, err)
+		}
+	}
+
+	if _, err := wt.Commit(message, &gogit.CommitOptions{Author: commitSignature}); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 
-	// Push
-	// if _, err := g.runGitCommand("push", "origin", branch); err != nil {
-	// 	return fmt.Errorf("failed to push: %w", err)
-	// }
+	refSpec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
+	if err := repo.Push(&gogit.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}}); err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// Worktree is a minimal read/write view over a single branch's tree,
+// scoped by cloneBranchInMemory so pages-branch operations never touch
+// the caller's actual working directory or HEAD.
+type Worktree interface {
+	// ReadFile reads path from the worktree.
+	ReadFile(path string) ([]byte, error)
+	// WriteFile writes path in the worktree, creating it if necessary.
+	WriteFile(path string, data []byte) error
+	// Remove deletes path from the worktree.
+	Remove(path string) error
+	// Exists reports whether path exists in the worktree.
+	Exists(path string) (bool, error)
+	// HasChanges reports whether any of paths has a staged or unstaged
+	// change relative to the worktree's current commit.
+	HasChanges(paths ...string) (bool, error)
+	// Commit stages every path in paths and commits them with message.
+	Commit(message string, paths ...string) error
+}
+
+// billyWorktree implements Worktree over a go-git worktree's billy.Filesystem.
+type billyWorktree struct {
+	wt *gogit.Worktree
+}
+
+func (w *billyWorktree) ReadFile(path string) ([]byte, error) {
+	f, err := w.wt.Filesystem.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (w *billyWorktree) WriteFile(path string, data []byte) error {
+	f, err := w.wt.Filesystem.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
+
+func (w *billyWorktree) Remove(path string) error {
+	_, err := w.wt.Remove(path)
+	return err
+}
+
+func (w *billyWorktree) Exists(path string) (bool, error) {
+	_, err := w.wt.Filesystem.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (w *billyWorktree) HasChanges(paths ...string) (bool, error) {
+	status, err := w.wt.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, path := range paths {
+		s, ok := status[path]
+		if ok && (s.Worktree != gogit.Unmodified || s.Staging != gogit.Unmodified) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
+func (w *billyWorktree) Commit(message string, paths ...string) error {
+	for _, path := range paths {
+		if _, err := w.wt.Add(path); err != nil {
+			return fmt.Errorf("failed to add %s: %w", path, err)
+		}
+	}
+	_, err := w.wt.Commit(message, &gogit.CommitOptions{Author: commitSignature})
+	return err
+}
+
+// cloneBranchInMemory clones branch from the "origin" remote into an
+// in-memory repository and returns a Worktree over it. Because the clone
+// is entirely in memory, reading or mutating it never touches the
+// caller's real working directory or HEAD, so it's safe to call from a
+// dirty working tree or from a CI runner that only has a release tag
+// checked out — unlike CheckoutBranch/PullBranch, which mutate the real
+// checkout. Callers that mutate the worktree finish by calling pushBranch
+// on the returned repository to publish their changes.
+func (g *Git) cloneBranchInMemory(branch string) (*gogit.Repository, Worktree, error) {
+	repo, err := g.open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	origin, err := repo.Remote("origin")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get origin remote: %w", err)
+	}
+	urls := origin.Config().URLs
+	if len(urls) == 0 {
+		return nil, nil, fmt.Errorf("origin remote has no URL")
+	}
+
+	memRepo, err := gogit.Clone(memory.NewStorage(), memfs.New(), &gogit.CloneOptions{
+		URL:           urls[0],
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to clone %s into memory: %w", branch, err)
+	}
+
+	memWt, err := memRepo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get in-memory worktree: %w", err)
+	}
+
+	return memRepo, &billyWorktree{wt: memWt}, nil
+}
+
+// pushBranch pushes an in-memory repository previously returned by
+// cloneBranchInMemory back to "origin".
+func (g *Git) pushBranch(memRepo *gogit.Repository) error {
+	if err := memRepo.Push(&gogit.PushOptions{RemoteName: "origin"}); err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// newTestRemoteAndClone creates a bare repository at <dir>/remote.git with a
+// single commit on branch, and a working clone of it at <dir>/work, wired
+// with an "origin" remote pointing at the bare repo. It returns the
+// work-tree path and a func to read branch's current hash straight from the
+// bare repo, so a test can assert that a push actually reached it.
+func newTestRemoteAndClone(t *testing.T, branch string) (workDir string, remoteHash func() plumbing.Hash) {
+	t.Helper()
+
+	base := t.TempDir()
+	remoteDir := filepath.Join(base, "remote.git")
+	workDir = filepath.Join(base, "work")
+
+	remoteRepo, err := gogit.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatalf("failed to init bare remote: %v", err)
+	}
+
+	workRepo, err := gogit.PlainInit(workDir, false)
+	if err != nil {
+		t.Fatalf("failed to init work repo: %v", err)
+	}
+
+	wt, err := workRepo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	// Commit on whatever branch PlainInit defaulted HEAD to - a brand new
+	// repository has no commit yet, so `checkout -b` can't be used until
+	// one exists.
+	seedPath := filepath.Join(workDir, "seed.txt")
+	if err := os.WriteFile(seedPath, []byte("seed\n"), 0644); err != nil {
+		t.Fatalf("failed to write seed file: %v", err)
+	}
+	if _, err := wt.Add("seed.txt"); err != nil {
+		t.Fatalf("failed to add seed file: %v", err)
+	}
+	if _, err := wt.Commit("seed commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com"},
+	}); err != nil {
+		t.Fatalf("failed to create seed commit: %v", err)
+	}
+
+	head, err := workRepo.Head()
+	if err != nil {
+		t.Fatalf("failed to resolve HEAD: %v", err)
+	}
+	if head.Name().Short() != branch {
+		if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}); err != nil {
+			t.Fatalf("failed to create branch %s: %v", branch, err)
+		}
+	}
+
+	if _, err := workRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("failed to add origin remote: %v", err)
+	}
+	refSpec := config.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch)
+	if err := workRepo.Push(&gogit.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}}); err != nil {
+		t.Fatalf("failed to push seed commit: %v", err)
+	}
+
+	remoteHash = func() plumbing.Hash {
+		ref, err := remoteRepo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		if err != nil {
+			t.Fatalf("failed to resolve %s on remote: %v", branch, err)
+		}
+		return ref.Hash()
+	}
+
+	return workDir, remoteHash
+}
+
+func TestGitCommitAndPushFilesPushesToRemote(t *testing.T) {
+	branch := "main"
+	workDir, remoteHash := newTestRemoteAndClone(t, branch)
+	before := remoteHash()
+
+	g := NewGit(workDir)
+	filePath := filepath.Join(workDir, "index.yaml")
+	if err := os.WriteFile(filePath, []byte("entries: {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write index.yaml: %v", err)
+	}
+
+	if err := g.CommitAndPushFiles([]string{"index.yaml"}, "update index", branch); err != nil {
+		t.Fatalf("CommitAndPushFiles failed: %v", err)
+	}
+
+	after := remoteHash()
+	if after == before {
+		t.Fatalf("expected remote %s ref to move past %s, but it didn't change", branch, before)
+	}
+}
+
+func TestGitCloneBranchInMemoryAndPushBranchPushesToRemote(t *testing.T) {
+	branch := "gh-pages"
+	workDir, remoteHash := newTestRemoteAndClone(t, branch)
+	before := remoteHash()
+
+	g := NewGit(workDir)
+	memRepo, wt, err := g.cloneBranchInMemory(branch)
+	if err != nil {
+		t.Fatalf("cloneBranchInMemory failed: %v", err)
+	}
+	if err := wt.WriteFile("index.yaml", []byte("entries: {}\n")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := wt.Commit("update index", "index.yaml"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := g.pushBranch(memRepo); err != nil {
+		t.Fatalf("pushBranch failed: %v", err)
+	}
+
+	after := remoteHash()
+	if after == before {
+		t.Fatalf("expected remote %s ref to move past %s, but it didn't change", branch, before)
+	}
+}
+
+func TestGitCloneBranchInMemoryLeavesRealCheckoutUntouched(t *testing.T) {
+	workDir := setupGitPagesRepo(t)
+
+	g := NewGit(workDir)
+	if err := os.WriteFile(filepath.Join(workDir, "dirty.txt"), []byte("uncommitted\n"), 0644); err != nil {
+		t.Fatalf("failed to write dirty.txt: %v", err)
+	}
+
+	if _, _, err := g.cloneBranchInMemory("gh-pages"); err != nil {
+		t.Fatalf("cloneBranchInMemory failed: %v", err)
+	}
+
+	branch, err := g.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("expected cloning gh-pages in memory to leave the real checkout on %q, got %q", "main", branch)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "dirty.txt")); err != nil {
+		t.Errorf("expected the real working tree's uncommitted file to survive, stat failed: %v", err)
+	}
+}
@@ -3,7 +3,9 @@ package internal
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -11,13 +13,18 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// defaultConcurrency is how many GitHub API calls a GHClient allows in
+// flight at once unless SetConcurrency overrides it.
+const defaultConcurrency = 4
+
 // GHClient GitHub client wrapper
 type GHClient struct {
-	client *github.Client
-	ctx    context.Context
-	owner  string
-	repo   string
-	logger logr.Logger
+	client  *github.Client
+	ctx     context.Context
+	owner   string
+	repo    string
+	limiter *rateLimiter
+	logger  logr.Logger
 }
 
 // NewGHClient creates a new GitHub client
@@ -40,14 +47,22 @@ func NewGHClient(owner, repo string, token string) (*GHClient, error) {
 	}
 
 	return &GHClient{
-		client: client,
-		ctx:    ctx,
-		owner:  owner,
-		repo:   repo,
-		logger: logger,
+		client:  client,
+		ctx:     ctx,
+		owner:   owner,
+		repo:    repo,
+		limiter: newRateLimiter(defaultConcurrency),
+		logger:  logger,
 	}, nil
 }
 
+// SetConcurrency configures how many GitHub API calls this client allows
+// in flight at once, used together with ReleaseManager.Concurrency to
+// bound parallel chart release deletes.
+func (gh *GHClient) SetConcurrency(concurrency int) {
+	gh.limiter = newRateLimiter(concurrency)
+}
+
 func (gh *GHClient) GetAllReleases() ([]*github.RepositoryRelease, error) {
 	gh.logger.Info("Getting all releases", "owner", gh.owner, "repo", gh.repo)
 
@@ -152,22 +167,105 @@ func (gh *GHClient) DeleteAllReleases() error {
 	return nil
 }
 
-// DeleteReleaseAndTag deletes release and corresponding tag
-func (gh *GHClient) DeleteReleaseAndTag(tag string) error {
-	log.Printf("Deleting release and tag: %s", tag)
+// DeleteReleaseAssetsWithSuffixes deletes assets attached to the release
+// tagged tag whose name ends in one of suffixes (e.g. ".tgz.prov",
+// ".tgz.sig"), without deleting the release itself. GHReleaseRegistry.Delete
+// calls it to drop provenance/signature sidecars before it deletes the
+// release and tag.
+//
+// Like DeleteRelease and DeleteTag above, the actual
+// Repositories.DeleteReleaseAsset call is left commented out rather than
+// wired up: this client is exercised against real repositories, and none
+// of its destructive GitHub API calls are enabled until that's backed by
+// integration coverage against a throwaway repo. Flip this on in the same
+// change that adds it.
+func (gh *GHClient) DeleteReleaseAssetsWithSuffixes(tag string, suffixes []string) error {
+	return gh.limiter.Do(func() error {
+		gh.logger.Info("Deleting release assets", "tag", tag, "suffixes", suffixes)
+
+		release, _, err := gh.client.Repositories.GetReleaseByTag(gh.ctx, gh.owner, gh.repo, tag)
+		if err != nil {
+			if isNotFoundError(err) {
+				gh.logger.Info("Release not found, skipping asset cleanup", "tag", tag)
+				return nil
+			}
+			return fmt.Errorf("failed to get release by tag %s: %w", tag, err)
+		}
+
+		for _, asset := range release.Assets {
+			if !hasAnySuffix(asset.GetName(), suffixes) {
+				continue
+			}
+
+			gh.logger.Info("Deleting release asset", "tag", tag, "asset", asset.GetName())
+			// _, err := gh.client.Repositories.DeleteReleaseAsset(gh.ctx, asset.GetID())
+			// if err != nil {
+			// 	return fmt.Errorf("failed to delete asset %s: %w", asset.GetName(), err)
+			// }
+		}
 
-	// First delete release
-	if err := gh.DeleteReleaseByTag(tag); err != nil {
-		return fmt.Errorf("failed to delete release: %w", err)
+		return nil
+	})
+}
+
+// DownloadReleaseAsset downloads the named asset attached to the release
+// tagged tag, e.g. "<chart>-<version>.tgz" or its ".prov" sibling.
+func (gh *GHClient) DownloadReleaseAsset(tag, assetName string) ([]byte, error) {
+	release, _, err := gh.client.Repositories.GetReleaseByTag(gh.ctx, gh.owner, gh.repo, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release by tag %s: %w", tag, err)
 	}
 
-	// Then delete tag
-	if err := gh.DeleteTag(tag); err != nil {
-		return fmt.Errorf("failed to delete tag: %w", err)
+	for _, asset := range release.Assets {
+		if asset.GetName() != assetName {
+			continue
+		}
+
+		rc, _, err := gh.client.Repositories.DownloadReleaseAsset(gh.ctx, gh.owner, gh.repo, asset.GetID(), http.DefaultClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download asset %s: %w", assetName, err)
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %w", assetName, err)
+		}
+		return data, nil
 	}
 
-	log.Printf("Successfully deleted release and tag: %s", tag)
-	return nil
+	return nil, fmt.Errorf("asset %s not found in release %s", assetName, tag)
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteReleaseAndTag deletes release and corresponding tag. The call is
+// bounded by the client's rate limiter, which also retries with backoff on
+// GitHub primary/secondary rate-limit errors.
+func (gh *GHClient) DeleteReleaseAndTag(tag string) error {
+	return gh.limiter.Do(func() error {
+		log.Printf("Deleting release and tag: %s", tag)
+
+		// First delete release
+		if err := gh.DeleteReleaseByTag(tag); err != nil {
+			return fmt.Errorf("failed to delete release: %w", err)
+		}
+
+		// Then delete tag
+		if err := gh.DeleteTag(tag); err != nil {
+			return fmt.Errorf("failed to delete tag: %w", err)
+		}
+
+		log.Printf("Successfully deleted release and tag: %s", tag)
+		return nil
+	})
 }
 
 // isNotFoundError checks if the error is a 404 error
@@ -2,190 +2,465 @@ package internal
 
 import (
 	"fmt"
-	"os"
+	"strings"
+	"sync"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/go-logr/logr"
 	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const indexYaml = "index.yaml"
 
-// IndexManager manages Helm chart index operations
+// IndexManager manages Helm chart index operations against a pluggable
+// Backend (a gh-pages index.yaml by default, or an OCI registry).
 type IndexManager struct {
-	Git            *Git
-	PagesBranch    string
-	OriginalBranch string
+	backend Backend
+
+	// DryRun, when true, computes and returns a CleanupPlan without
+	// calling RemoveAll/RemoveVersion/Commit on the backend.
+	DryRun bool
+
+	// Force skips the digest verification CleanEntriesVersions,
+	// CleanMatchingEntries, CleanEntriesByConstraint, and PruneOldVersions
+	// otherwise perform before deleting a version.
+	Force bool
+
+	// mu serializes index mutations: the backend's loaded index is an
+	// in-memory map that is not safe for concurrent writers, even though
+	// callers may delete releases for several charts in parallel.
+	mu sync.Mutex
 
 	logger logr.Logger
 }
 
-// NewIndexManager creates a new IndexManager
-func NewIndexManager(git *Git, pagesBranch string) *IndexManager {
+// NewIndexManager creates a new IndexManager. backend accepts either a
+// plain pages branch name (e.g. "gh-pages", for backward compatibility), a
+// "git+https://...#branch" URL, or an "oci://host/repository" URL.
+func NewIndexManager(git *Git, backend string) *IndexManager {
 	return &IndexManager{
-		Git:         git,
-		PagesBranch: pagesBranch,
-		logger:      Logger.WithName("index"),
+		backend: parseBackend(git, backend),
+		logger:  Logger.WithName("index"),
+	}
+}
+
+// parseBackend resolves a backend URL/branch-name into a concrete Backend.
+func parseBackend(git *Git, backend string) Backend {
+	switch {
+	case strings.HasPrefix(backend, "oci://"):
+		return NewOCIBackend(strings.TrimPrefix(backend, "oci://"))
+	case strings.HasPrefix(backend, "git+"):
+		rest := strings.TrimPrefix(backend, "git+")
+		pagesBranch := "gh-pages"
+		if idx := strings.LastIndex(rest, "#"); idx != -1 {
+			pagesBranch = rest[idx+1:]
+		}
+		return NewGitPagesBackend(git, pagesBranch)
+	default:
+		// A bare string is treated as the pages branch name.
+		return NewGitPagesBackend(git, backend)
 	}
 }
 
-// preparePagesBranch prepares the pages branch for index operations
-func (i *IndexManager) preparePagesBranch() error {
-	// Save current branch
-	currentBranch, err := i.Git.GetCurrentBranch()
+// LoadIndex returns the current index without removing or committing any
+// entries; callers such as ReleaseManager.VerifyReleases use it to read
+// recorded digests before deciding whether a delete is safe. It's safe to
+// call on an IndexManager that a later Clean* call also runs against (e.g.
+// VerifyReleases followed by DeleteChangedCharts' cleanup): GitPagesBackend
+// reads the pages branch through an in-memory clone rather than checking it
+// out, so repeated calls never touch the caller's real checkout at all.
+func (i *IndexManager) LoadIndex() (*repo.IndexFile, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	indexFile, err := i.backend.Load()
 	if err != nil {
-		return fmt.Errorf("failed to get current branch: %w", err)
+		return nil, fmt.Errorf("failed to load index: %w", err)
 	}
-	i.OriginalBranch = currentBranch
+	return indexFile, nil
+}
+
+// CleanAllEntries removes every entry from the index. In DryRun mode it
+// returns the CleanupPlan without touching the backend.
+func (i *IndexManager) CleanAllEntries() (*CleanupPlan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	// Check if pages branch exists
-	if !i.Git.BranchExists(i.PagesBranch) {
-		i.logger.Info("%s branch does not exist. Skipping index cleanup.", i.PagesBranch)
-		return fmt.Errorf("pages branch does not exist")
+	indexFile, err := i.backend.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
 	}
 
-	// Fetch and checkout pages branch
-	if err := i.Git.FetchBranch(i.PagesBranch); err != nil {
-		return fmt.Errorf("failed to fetch %s branch: %w", i.PagesBranch, err)
+	plan := &CleanupPlan{}
+	for name, versions := range indexFile.Entries {
+		for _, v := range versions {
+			plan.IndexEntries = append(plan.IndexEntries, CleanupPlanEntry{Name: name, Version: v.Version})
+		}
 	}
 
-	if err := i.Git.CheckoutBranch(i.PagesBranch); err != nil {
-		return fmt.Errorf("failed to checkout %s branch: %w", i.PagesBranch, err)
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping index mutation", "entries", len(plan.IndexEntries))
+		return plan, nil
 	}
 
-	// Pull latest changes
-	if err := i.Git.PullBranch(i.PagesBranch); err != nil {
-		i.logger.Info("Failed to pull latest changes from %s: %v", i.PagesBranch, err)
+	if err := i.backend.RemoveAll(); err != nil {
+		return nil, fmt.Errorf("failed to remove all entries: %w", err)
 	}
 
-	// Check if index.yaml exists
-	if _, err := os.Stat(indexYaml); os.IsNotExist(err) {
-		i.logger.Info("index.yaml not found in %s branch. Skipping index cleanup.", i.PagesBranch)
-		i.restoreOriginalBranch()
-		return fmt.Errorf("index.yaml not found")
+	if err := i.backend.Commit("Cleaned all chart entries from index.yaml"); err != nil {
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
 	}
 
-	return nil
+	i.logger.Info("All chart entries removed from index")
+	return plan, nil
 }
 
-func (i *IndexManager) commitIndexChanges(message string) error {
-	if changed := i.Git.HasChanges(indexYaml); !changed {
-		i.logger.Info("No changes detected in %s, skipping commit", indexYaml)
-		return nil
+// CleanEntriesVersions removes charts with specific versions. In DryRun
+// mode it returns the CleanupPlan without touching the backend.
+func (i *IndexManager) CleanEntriesVersions(charts []*ChartInfo) (*CleanupPlan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	indexFile, err := i.backend.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
 	}
 
-	if err := i.Git.CommitAndPush(indexYaml, message, i.PagesBranch); err != nil {
-		return fmt.Errorf("failed to commit and push changes: %w", err)
+	plan := &CleanupPlan{}
+	for _, chart := range charts {
+		for _, v := range indexFile.Entries[chart.Name] {
+			if v.Version == chart.Version {
+				plan.IndexEntries = append(plan.IndexEntries, CleanupPlanEntry{Name: chart.Name, Version: chart.Version})
+			}
+		}
 	}
-	i.logger.Info("Successfully committed and pushed changes to %s branch", i.PagesBranch)
-	return nil
-}
 
-// restoreOriginalBranch restores the original branch
-func (i *IndexManager) restoreOriginalBranch() error {
-	if i.OriginalBranch != "" {
-		return i.Git.CheckoutBranch(i.OriginalBranch)
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping index mutation", "entries", len(plan.IndexEntries))
+		return plan, nil
 	}
-	return nil
-}
 
-// loadIndexFile loads the index.yaml file
-func (i *IndexManager) loadIndexFile() (*repo.IndexFile, error) {
-	var indexFile *repo.IndexFile
-	_, err := os.Stat(indexYaml)
-	if os.IsNotExist(err) {
-		return repo.NewIndexFile(), nil
-	} else if err != nil {
-		return nil, fmt.Errorf("failed to stat index.yaml: %w", err)
+	for _, chart := range charts {
+		if err := i.checkDigest(indexFile, chart.Name, chart.Version); err != nil {
+			return nil, err
+		}
+		if err := i.backend.RemoveVersion(chart.Name, chart.Version); err != nil {
+			return nil, fmt.Errorf("failed to remove %s@%s: %w", chart.Name, chart.Version, err)
+		}
 	}
 
-	indexFile, err = repo.LoadIndexFile(indexYaml)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load index.yaml: %w", err)
+	if err := i.backend.Commit("Removed specified chart versions from index.yaml"); err != nil {
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
 	}
 
-	return indexFile, nil
+	i.logger.Info("Removed specified chart versions from index")
+	return plan, nil
 }
 
-// writeIndexFile writes the index.yaml file
-func (i *IndexManager) writeIndexFile(indexFile *repo.IndexFile) error {
-	if err := indexFile.WriteFile(indexYaml, 0644); err != nil {
-		return fmt.Errorf("failed to write index.yaml: %w", err)
+// checkDigest refuses to delete name@version unless i.Force is set, the
+// backend can't verify digests (e.g. OCIBackend), the index has no
+// recorded digest for it, or the recorded digest matches the on-disk
+// chart archive.
+func (i *IndexManager) checkDigest(indexFile *repo.IndexFile, name, version string) error {
+	if i.Force {
+		return nil
+	}
+
+	verifier, ok := i.backend.(DigestVerifier)
+	if !ok {
+		return nil
+	}
+
+	var digest string
+	for _, v := range indexFile.Entries[name] {
+		if v.Version == version {
+			digest = v.Digest
+			break
+		}
+	}
+
+	matches, err := verifier.VerifyDigest(name, version, digest)
+	if err != nil {
+		return fmt.Errorf("failed to verify digest for %s@%s: %w", name, version, err)
+	}
+	if !matches {
+		return fmt.Errorf("digest mismatch for %s@%s: refusing to delete without --force", name, version)
 	}
-	i.logger.Info("Successfully wrote index.yaml")
 	return nil
 }
 
-// CleanChartEntry removes a specific chart version from the index.yaml
-func (i *IndexManager) CleanAllEntries() error {
-	if err := i.preparePagesBranch(); err != nil {
-		return fmt.Errorf("failed to prepare pages branch: %w", err)
-	}
-	defer i.restoreOriginalBranch()
+// CleanMatchingEntries removes every index entry matched by selector,
+// except for the keepLast newest semver versions of each chart (keepLast
+// <= 0 disables this safety net). In DryRun mode it returns the
+// CleanupPlan without touching the backend.
+func (i *IndexManager) CleanMatchingEntries(selector *Selector, keepLast int) (*CleanupPlan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	indexFile, err := i.loadIndexFile()
+	indexFile, err := i.backend.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load index file: %w", err)
+		return nil, fmt.Errorf("failed to load index: %w", err)
 	}
 
-	indexFile.Entries = make(map[string]repo.ChartVersions, 0) // Clear all entries
+	plan := &CleanupPlan{}
+	var toRemove []*ChartInfo
+	for name, versions := range indexFile.Entries {
+		kept := newestVersions(versions, keepLast)
+		for _, v := range versions {
+			if kept[v.Version] {
+				continue
+			}
+			chart := ChartInfo{Name: name, Version: v.Version}
+			if !selector.Matches(chart) {
+				continue
+			}
+			plan.IndexEntries = append(plan.IndexEntries, CleanupPlanEntry{Name: name, Version: v.Version})
+			toRemove = append(toRemove, &chart)
+		}
+	}
 
-	if err := i.writeIndexFile(indexFile); err != nil {
-		return fmt.Errorf("failed to write index file: %w", err)
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping index mutation", "entries", len(plan.IndexEntries))
+		return plan, nil
 	}
 
-	i.logger.Info("All chart entries removed from index.yaml")
+	for _, chart := range toRemove {
+		if err := i.checkDigest(indexFile, chart.Name, chart.Version); err != nil {
+			return nil, err
+		}
+		if err := i.backend.RemoveVersion(chart.Name, chart.Version); err != nil {
+			return nil, fmt.Errorf("failed to remove %s@%s: %w", chart.Name, chart.Version, err)
+		}
+	}
 
-	if err := i.commitIndexChanges("Cleaned all chart entries from index.yaml"); err != nil {
-		return fmt.Errorf("failed to commit changes: %w", err)
+	if err := i.backend.Commit("Removed chart versions matching selector from index.yaml"); err != nil {
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
 	}
-	return nil
+
+	i.logger.Info("Removed chart versions matching selector from index")
+	return plan, nil
 }
 
-// CleanEntriesVersions removes charts with specific versions
-func (i *IndexManager) CleanEntriesVersions(charts []*ChartInfo) error {
-	if err := i.preparePagesBranch(); err != nil {
-		return fmt.Errorf("failed to prepare pages branch: %w", err)
+// CleanEntriesByConstraint removes every version of chartName satisfying
+// the SemVer constraint (e.g. "<0.2.0", ">=1.0.0 <2.0.0"), using
+// Masterminds/semver/v3. In DryRun mode it returns the CleanupPlan without
+// touching the backend.
+func (i *IndexManager) CleanEntriesByConstraint(chartName, constraint string) (*CleanupPlan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %q: %w", constraint, err)
 	}
-	defer i.restoreOriginalBranch()
 
-	indexFile, err := i.loadIndexFile()
+	indexFile, err := i.backend.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load index file: %w", err)
+		return nil, fmt.Errorf("failed to load index: %w", err)
 	}
 
-	for _, chart := range charts {
-		if _, exists := indexFile.Entries[chart.Name]; !exists {
-			i.logger.Info("No entries found for chart %s", chart.Name)
+	plan := &CleanupPlan{}
+	var matched []string
+	for _, v := range indexFile.Entries[chartName] {
+		sv, err := semver.NewVersion(v.Version)
+		if err != nil {
+			i.logger.Info("Skipping unparseable version", "chart", chartName, "version", v.Version, "error", err)
+			continue
+		}
+		if !c.Check(sv) {
 			continue
 		}
+		plan.IndexEntries = append(plan.IndexEntries, CleanupPlanEntry{Name: chartName, Version: v.Version})
+		matched = append(matched, v.Version)
+	}
+
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping index mutation", "entries", len(plan.IndexEntries))
+		return plan, nil
+	}
+
+	for _, version := range matched {
+		if err := i.checkDigest(indexFile, chartName, version); err != nil {
+			return nil, err
+		}
+		if err := i.backend.RemoveVersion(chartName, version); err != nil {
+			return nil, fmt.Errorf("failed to remove %s@%s: %w", chartName, version, err)
+		}
+	}
+
+	if err := i.backend.Commit(fmt.Sprintf("Removed %s versions matching %s from index.yaml", chartName, constraint)); err != nil {
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	i.logger.Info("Removed chart versions matching constraint from index", "chart", chartName, "constraint", constraint)
+	return plan, nil
+}
+
+// PruneOldVersions keeps only the newest keepN SemVer versions of each
+// chart in the index, removing the rest. Versions that don't parse as
+// SemVer are left untouched, since they can't be ordered against the
+// ones that do. In DryRun mode it returns the CleanupPlan without
+// touching the backend.
+func (i *IndexManager) PruneOldVersions(keepN int) (*CleanupPlan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-		// Remove the specific chart versions
-		existVersions := indexFile.Entries[chart.Name]
-		filteredVersions := make(repo.ChartVersions, 0, len(existVersions))
-		for _, version := range existVersions {
-			found := false
-			if version.Version == chart.Version {
-				found = true
+	indexFile, err := i.backend.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+
+	plan := &CleanupPlan{}
+	type toRemoveEntry struct{ name, version string }
+	var toRemove []toRemoveEntry
+	for name, versions := range indexFile.Entries {
+		kept := newestVersions(versions, keepN)
+		for _, v := range versions {
+			if _, err := semver.NewVersion(v.Version); err != nil {
+				continue
 			}
-			if !found {
-				filteredVersions = append(filteredVersions, version)
+			if kept[v.Version] {
+				continue
 			}
+			plan.IndexEntries = append(plan.IndexEntries, CleanupPlanEntry{Name: name, Version: v.Version})
+			toRemove = append(toRemove, toRemoveEntry{name: name, version: v.Version})
+		}
+	}
+
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping index mutation", "entries", len(plan.IndexEntries))
+		return plan, nil
+	}
+
+	for _, entry := range toRemove {
+		if err := i.checkDigest(indexFile, entry.name, entry.version); err != nil {
+			return nil, err
 		}
-		if len(filteredVersions) == 0 {
-			delete(indexFile.Entries, chart.Name)
-		} else {
-			indexFile.Entries[chart.Name] = filteredVersions
+		if err := i.backend.RemoveVersion(entry.name, entry.version); err != nil {
+			return nil, fmt.Errorf("failed to remove %s@%s: %w", entry.name, entry.version, err)
+		}
+	}
+
+	if err := i.backend.Commit(fmt.Sprintf("Pruned all but the newest %d versions of each chart from index.yaml", keepN)); err != nil {
+		return nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	i.logger.Info("Pruned old chart versions from index", "keepN", keepN)
+	return plan, nil
+}
+
+// findChartVersion locates name@version's ChartVersion entry in indexFile.
+func findChartVersion(indexFile *repo.IndexFile, name, version string) (*repo.ChartVersion, error) {
+	for _, v := range indexFile.Entries[name] {
+		if v.Version == version {
+			return v, nil
 		}
 	}
+	return nil, fmt.Errorf("no index entry found for %s@%s", name, version)
+}
+
+// SetLabels merges labels into the "annotations" recorded for
+// chartName@version in index.yaml (e.g. a release channel, a
+// security-advisory ID, or a minimum kubedoop version), creating the
+// entry's annotation map if it doesn't have one yet.
+func (i *IndexManager) SetLabels(chartName, version string, newLabels map[string]string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	indexFile, err := i.backend.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load index: %w", err)
+	}
 
-	if err := i.writeIndexFile(indexFile); err != nil {
-		return fmt.Errorf("failed to write index file: %w", err)
+	v, err := findChartVersion(indexFile, chartName, version)
+	if err != nil {
+		return err
+	}
+
+	if v.Annotations == nil {
+		v.Annotations = make(map[string]string, len(newLabels))
+	}
+	for k, val := range newLabels {
+		v.Annotations[k] = val
 	}
 
-	i.logger.Info("Removed specified chart versions from index.yaml")
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping annotation commit", "chart", chartName, "version", version)
+		return nil
+	}
 
-	if err := i.commitIndexChanges("Removed specified chart versions from index.yaml"); err != nil {
+	if err := i.backend.Commit(fmt.Sprintf("Set labels on %s@%s in index.yaml", chartName, version)); err != nil {
 		return fmt.Errorf("failed to commit changes: %w", err)
 	}
+
+	i.logger.Info("Set labels on chart index entry", "chart", chartName, "version", version, "labels", newLabels)
 	return nil
 }
+
+// RemoveLabels deletes the given annotation keys from chartName@version's
+// recorded annotations in index.yaml. Omitting keys clears every
+// annotation on that entry.
+func (i *IndexManager) RemoveLabels(chartName, version string, keys ...string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	indexFile, err := i.backend.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load index: %w", err)
+	}
+
+	v, err := findChartVersion(indexFile, chartName, version)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		v.Annotations = nil
+	} else {
+		for _, k := range keys {
+			delete(v.Annotations, k)
+		}
+	}
+
+	if i.DryRun {
+		i.logger.Info("Dry run: skipping annotation commit", "chart", chartName, "version", version)
+		return nil
+	}
+
+	if err := i.backend.Commit(fmt.Sprintf("Removed labels from %s@%s in index.yaml", chartName, version)); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	i.logger.Info("Removed labels from chart index entry", "chart", chartName, "version", version, "keys", keys)
+	return nil
+}
+
+// ListByLabelSelector returns every indexed chart version whose
+// annotations satisfy a Kubernetes-style label selector, e.g.
+// "channel=stable" or "channel in (stable,beta)".
+func (i *IndexManager) ListByLabelSelector(selector string) ([]*ChartInfo, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+
+	indexFile, err := i.backend.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+
+	var matched []*ChartInfo
+	for name, versions := range indexFile.Entries {
+		for _, v := range versions {
+			if sel.Matches(labels.Set(v.Annotations)) {
+				matched = append(matched, &ChartInfo{Name: name, Version: v.Version})
+			}
+		}
+	}
+
+	return matched, nil
+}
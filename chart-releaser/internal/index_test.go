@@ -0,0 +1,57 @@
+package internal
+
+import "testing"
+
+// TestIndexManagerLoadIndexThenCleanRestoresOriginalBranch checks that a
+// LoadIndex (as ReleaseManager.VerifyReleases does) followed by a
+// CleanEntriesVersions on the same IndexManager (as
+// ReleaseManager.DeleteChangedCharts does) leaves the real checkout on the
+// branch it started on.
+func TestIndexManagerLoadIndexThenCleanRestoresOriginalBranch(t *testing.T) {
+	workDir := setupGitPagesRepo(t)
+	chdir(t, workDir)
+
+	git := NewGit(workDir)
+	im := NewIndexManager(git, "gh-pages")
+
+	if _, err := im.LoadIndex(); err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+
+	if _, err := im.CleanEntriesVersions(nil); err != nil {
+		t.Fatalf("CleanEntriesVersions failed: %v", err)
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("expected reusing the IndexManager across LoadIndex and CleanEntriesVersions to restore %q, got %q", "main", branch)
+	}
+}
+
+// TestIndexManagerDryRunCleanAllEntriesRestoresOriginalBranch checks that
+// CleanAllEntries in DryRun mode, which returns before any Commit, leaves
+// the real checkout on the branch it started on rather than on the pages
+// branch.
+func TestIndexManagerDryRunCleanAllEntriesRestoresOriginalBranch(t *testing.T) {
+	workDir := setupGitPagesRepo(t)
+	chdir(t, workDir)
+
+	git := NewGit(workDir)
+	im := NewIndexManager(git, "gh-pages")
+	im.DryRun = true
+
+	if _, err := im.CleanAllEntries(); err != nil {
+		t.Fatalf("CleanAllEntries failed: %v", err)
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("expected a dry run to restore %q, got %q", "main", branch)
+	}
+}
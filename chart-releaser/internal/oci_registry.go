@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// ociRegistryClient wraps the oras-go remote repository client used by
+// OCIBackend. helm.sh/helm/v3/pkg/registry.Client is built for push/pull of
+// a single chart and has no tag-listing or delete API, so we talk to the
+// registry directly via oras, the same way Helm's own OCI support does
+// underneath the covers.
+type ociRegistryClient struct {
+	repo *remote.Repository
+}
+
+func newOCIRegistryClient(repository string) (*ociRegistryClient, error) {
+	repo, err := remote.NewRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+	return &ociRegistryClient{repo: repo}, nil
+}
+
+// ListTags returns every tag present in the repository.
+func (c *ociRegistryClient) ListTags(repository string) ([]string, error) {
+	ctx := context.Background()
+
+	var tags []string
+	err := c.repo.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// DeleteTag deletes the manifest referenced by tag.
+func (c *ociRegistryClient) DeleteTag(repository, tag string) error {
+	ctx := context.Background()
+
+	desc, err := c.repo.Resolve(ctx, tag)
+	if err != nil {
+		return err
+	}
+
+	return c.repo.Delete(ctx, desc)
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CleanupPlan describes what a cleanup run would change, without actually
+// mutating anything. IndexManager and ReleaseManager both produce one on
+// every run; in --dry-run mode no commit, push, or GitHub delete happens,
+// and the plan is the only record of what would have.
+type CleanupPlan struct {
+	IndexEntries []CleanupPlanEntry `json:"indexEntries" yaml:"indexEntries"`
+	Releases     []string           `json:"releases,omitempty" yaml:"releases,omitempty"`
+	Tags         []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// CleanupPlanEntry is a single chart version that would be removed from
+// the index.
+type CleanupPlanEntry struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// Print writes the plan to w as "json" or "yaml" (the default).
+func (p *CleanupPlan) Print(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	case "yaml", "":
+		data, err := yaml.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cleanup plan: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported plan format %q", format)
+	}
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+)
+
+// chartSidecarSuffixes are the file suffixes that accompany a packaged
+// chart tarball: the tarball itself, its Helm provenance signature, and
+// sigstore/cosign detached signatures.
+var chartSidecarSuffixes = []string{".tgz", ".tgz.prov", ".tgz.sig", ".tgz.asc"}
+
+// ProvenanceCleaner removes a chart's packaged tarball and its provenance
+// or signature sidecars from a pages-branch worktree, so removing a
+// version from index.yaml doesn't leave orphaned files behind that break
+// `helm verify` for consumers who cache the repo.
+type ProvenanceCleaner struct {
+	logger logr.Logger
+}
+
+// NewProvenanceCleaner creates a new ProvenanceCleaner.
+func NewProvenanceCleaner() *ProvenanceCleaner {
+	return &ProvenanceCleaner{logger: Logger.WithName("provenance-cleaner")}
+}
+
+// Clean deletes "<chart.Name>-<chart.Version>.tgz" and its sidecars from
+// wt, returning the paths it removed.
+func (p *ProvenanceCleaner) Clean(wt Worktree, chart *ChartInfo) ([]string, error) {
+	base := fmt.Sprintf("%s-%s", chart.Name, chart.Version)
+
+	var removed []string
+	for _, suffix := range chartSidecarSuffixes {
+		path := base + suffix
+		exists, err := wt.Exists(path)
+		if err != nil {
+			return removed, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if !exists {
+			continue
+		}
+		if err := wt.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		p.logger.Info("Removed sidecar file", "path", path)
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// rateLimiter bounds how many GitHub API calls may be in flight at once
+// and retries with exponential backoff when GitHub signals a rate limit,
+// honoring the X-RateLimit-Remaining / Retry-After response headers
+// go-github surfaces as typed errors.
+type rateLimiter struct {
+	tokens chan struct{}
+}
+
+// newRateLimiter creates a token-bucket limiter allowing at most
+// concurrency in-flight calls.
+func newRateLimiter(concurrency int) *rateLimiter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	tokens := make(chan struct{}, concurrency)
+	for i := 0; i < concurrency; i++ {
+		tokens <- struct{}{}
+	}
+	return &rateLimiter{tokens: tokens}
+}
+
+// maxRetryAttempts bounds how many times Do retries a rate-limited call
+// before giving up and returning the last error.
+const maxRetryAttempts = 5
+
+// Do acquires a token, runs fn, and retries with backoff if fn fails with
+// a rate-limit error, releasing the token once fn has finished retrying.
+func (r *rateLimiter) Do(fn func() error) error {
+	<-r.tokens
+	defer func() { r.tokens <- struct{}{} }()
+
+	var err error
+	for attempt := 0; attempt < maxRetryAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		wait, retryable := rateLimitWait(err, attempt)
+		if !retryable {
+			return err
+		}
+		time.Sleep(wait)
+	}
+	return err
+}
+
+// rateLimitWait inspects err for GitHub's primary and secondary
+// (abuse-detection) rate-limit error types and returns how long to wait
+// before retrying.
+func rateLimitWait(err error, attempt int) (time.Duration, bool) {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return time.Until(rateErr.Rate.Reset.Time), true
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return exponentialBackoff(attempt), true
+	}
+
+	return 0, false
+}
+
+func exponentialBackoff(attempt int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempt))) * time.Second
+}
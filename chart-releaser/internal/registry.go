@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+)
+
+// ChartRegistry deletes a single chart version's published artifact from
+// wherever it is hosted. ReleaseManager calls Delete on every configured
+// registry for each chart it deletes, so one cleanup run can roll back a
+// chart from every surface it was published to (a GitHub release, one or
+// more OCI registries, ...).
+type ChartRegistry interface {
+	Delete(name, version string) error
+}
+
+// GHReleaseRegistry deletes a chart version's GitHub release, tag, and
+// orphaned provenance/signature assets. It is the default ChartRegistry
+// every ReleaseManager is configured with.
+type GHReleaseRegistry struct {
+	ghc    *GHClient
+	logger logr.Logger
+}
+
+// NewGHReleaseRegistry creates a ChartRegistry backed by ghc.
+func NewGHReleaseRegistry(ghc *GHClient) *GHReleaseRegistry {
+	return &GHReleaseRegistry{ghc: ghc, logger: Logger.WithName("gh-release-registry")}
+}
+
+// Delete removes the GitHub release and tag named "<name>-<version>".
+func (r *GHReleaseRegistry) Delete(name, version string) error {
+	releaseName := fmt.Sprintf("%s-%s", name, version)
+
+	if err := r.ghc.DeleteReleaseAssetsWithSuffixes(releaseName, provenanceAssetSuffixes); err != nil {
+		r.logger.Info("Failed to clean provenance assets", "release", releaseName, "error", err)
+	}
+
+	r.logger.Info("Deleting release", "release", releaseName)
+	if err := r.ghc.DeleteReleaseAndTag(releaseName); err != nil {
+		return fmt.Errorf("failed to delete release %s: %w", releaseName, err)
+	}
+	return nil
+}
+
+// OCIRegistry deletes a chart version's manifest from an OCI-compliant
+// registry (ghcr.io, Harbor, Docker Hub) addressed by the "<chart>-<version>"
+// tag convention `helm push` uses. It delegates to an OCIBackend so the
+// tag-deletion logic isn't duplicated between the index backend and the
+// release-artifact registry.
+type OCIRegistry struct {
+	backend *OCIBackend
+}
+
+// NewOCIRegistry creates a ChartRegistry backed by the OCI repository
+// (e.g. "ghcr.io/zncdatadev/charts", without the "oci://" prefix).
+func NewOCIRegistry(repository string) *OCIRegistry {
+	return &OCIRegistry{backend: NewOCIBackend(repository)}
+}
+
+// Delete removes the manifest tagged "<name>-<version>".
+func (r *OCIRegistry) Delete(name, version string) error {
+	return r.backend.RemoveVersion(name, version)
+}
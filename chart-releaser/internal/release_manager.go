@@ -6,15 +6,23 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"gopkg.in/yaml.v3"
 )
 
+// provenanceAssetSuffixes are the release-asset name suffixes cleaned up
+// alongside a chart's own tarball asset; see ProvenanceCleaner for the
+// pages-branch equivalent.
+var provenanceAssetSuffixes = []string{".tgz.prov", ".tgz.sig", ".tgz.asc"}
+
 // ChartMetadata represents the Chart.yaml structure
 type ChartMetadata struct {
-	Name    string `yaml:"name"`
-	Version string `yaml:"version"`
+	APIVersion   string            `yaml:"apiVersion"`
+	Name         string            `yaml:"name"`
+	Version      string            `yaml:"version"`
+	Dependencies []ChartDependency `yaml:"dependencies"`
 }
 
 // ChartInfo represents information about a Helm chart
@@ -33,6 +41,33 @@ type ReleaseManager struct {
 	chartDir       string
 	versionPattern string
 
+	// DryRun, when true, computes and returns a CleanupPlan without
+	// deleting any release, tag, or index entry.
+	DryRun bool
+
+	// Concurrency bounds how many chart releases are deleted in parallel.
+	// Defaults to defaultConcurrency.
+	Concurrency int
+
+	// Registries are deleted from for every chart this ReleaseManager
+	// removes, in addition to the index. Defaults to a single
+	// GHReleaseRegistry; callers append additional registries (e.g.
+	// OCIRegistry) to roll a chart back from every surface it was
+	// published to.
+	Registries []ChartRegistry
+
+	// KeyringPath, when set, causes DeleteChangedCharts to call
+	// VerifyReleases against every chart it's about to delete first and
+	// abort without deleting anything if any release's signature or
+	// digest doesn't check out.
+	KeyringPath string
+
+	// LabelSelector, when set, restricts DeleteChangedCharts to changed
+	// charts whose index entry matches this Kubernetes-style label
+	// selector (e.g. "channel=nightly"), so a scheduled cleanup can prune
+	// one release channel without ever touching another.
+	LabelSelector string
+
 	logger logr.Logger
 }
 
@@ -52,6 +87,8 @@ func NewReleaseManager(
 		versionPattern: versionPattern,
 		index:          index,
 		ghc:            ghc,
+		Concurrency:    defaultConcurrency,
+		Registries:     []ChartRegistry{NewGHReleaseRegistry(ghc)},
 
 		logger: Logger.WithName("release-manager"),
 	}
@@ -96,10 +133,42 @@ func (m *ReleaseManager) versionMatchesPattern(version, pattern string) (bool, e
 	return regex.MatchString(version), nil
 }
 
-// GetChangedCharts gets list of changed charts that match the version pattern
-func (m *ReleaseManager) getChangedCharts(changedFiles []string, versionPattern string) ([]*ChartInfo, error) {
-	var changedCharts []*ChartInfo
+// listAllCharts scans every Helm chart directly under m.chartDir and
+// returns a map from chart name to its ChartInfo. It's shared by
+// getChangedCharts and ResolveDependencyGraph so both see the same view
+// of the chart tree.
+func (m *ReleaseManager) listAllCharts() (map[string]*ChartInfo, error) {
+	entries, err := os.ReadDir(m.chartDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart dir %s: %w", m.chartDir, err)
+	}
 
+	charts := make(map[string]*ChartInfo)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		chartPath := filepath.Join(m.chartDir, entry.Name())
+		if !m.isHelmChart(chartPath) {
+			continue
+		}
+		chartInfo, err := m.getChartInfo(chartPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart info for %s: %w", chartPath, err)
+		}
+		charts[chartInfo.Name] = chartInfo
+	}
+	return charts, nil
+}
+
+// GetChangedCharts gets the list of changed charts that match the version
+// pattern, in the order their releases should be deleted in. A chart whose
+// own files didn't change is still included if it locally depends (via a
+// "file://" dependency) on one that did, directly or transitively; see
+// ResolveDependencyGraph. The result is topologically sorted so that a
+// chart is always ordered before the local dependencies it depends on,
+// i.e. parents before children.
+func (m *ReleaseManager) getChangedCharts(changedFiles []string, versionPattern string) ([]*ChartInfo, error) {
 	// Extract unique chart directories from changed files
 	chartDirs := make(map[string]bool)
 	for _, filePath := range changedFiles {
@@ -110,95 +179,266 @@ func (m *ReleaseManager) getChangedCharts(changedFiles []string, versionPattern
 		}
 	}
 
-	// Filter and validate charts
+	allCharts, err := m.listAllCharts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list charts: %w", err)
+	}
+
+	changedNames := make(map[string]bool)
 	for chartDir := range chartDirs {
 		if !m.isHelmChart(chartDir) {
-			m.logger.Info("%s is not a Helm chart. Skipping.", chartDir)
+			m.logger.Info("Not a Helm chart, skipping", "dir", chartDir)
 			continue
 		}
 
 		chartInfo, err := m.getChartInfo(chartDir)
 		if err != nil {
-			m.logger.Info("Error getting chart info for %s: %v", chartDir, err)
+			m.logger.Info("Error getting chart info", "dir", chartDir, "error", err)
 			continue
 		}
 
+		changedNames[chartInfo.Name] = true
+	}
+
+	graph, err := m.ResolveDependencyGraph()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chart dependency graph: %w", err)
+	}
+
+	// dependents maps a chart to the charts that locally depend on it, so
+	// a change can be propagated downstream.
+	dependents := make(map[string][]string)
+	for name, deps := range graph {
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	queue := make([]string, 0, len(changedNames))
+	for name := range changedNames {
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[name] {
+			if changedNames[dependent] {
+				continue
+			}
+			changedNames[dependent] = true
+			queue = append(queue, dependent)
+		}
+	}
+
+	for name := range changedNames {
+		chartInfo, ok := allCharts[name]
+		if !ok {
+			return nil, fmt.Errorf("chart %s is referenced as changed but was not found under %s", name, m.chartDir)
+		}
+
 		matches, err := m.versionMatchesPattern(chartInfo.Version, versionPattern)
 		if err != nil {
 			return nil, fmt.Errorf("error checking version pattern: %w", err)
 		}
-
 		if !matches {
 			return nil, fmt.Errorf("chart version %s does not match supported pattern for deletion: %s", chartInfo.Version, versionPattern)
 		}
 
-		m.logger.Info("Found Helm chart: %s with version %s", chartDir, chartInfo.Version)
-		changedCharts = append(changedCharts, chartInfo)
+		m.logger.Info("Found Helm chart", "chart", name, "version", chartInfo.Version)
+	}
+
+	order, err := topologicalOrder(sortedKeys(changedNames), graph)
+	if err != nil {
+		return nil, err
+	}
+
+	changedCharts := make([]*ChartInfo, 0, len(changedNames))
+	for _, name := range order {
+		if chartInfo, ok := allCharts[name]; ok && changedNames[name] {
+			changedCharts = append(changedCharts, chartInfo)
+		}
 	}
 
 	return changedCharts, nil
 }
 
-func (m *ReleaseManager) DeleteAllReleases() error {
+func (m *ReleaseManager) DeleteAllReleases() (*CleanupPlan, error) {
+	releases, err := m.ghc.GetAllReleases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get releases: %w", err)
+	}
+
+	plan := &CleanupPlan{}
+	for _, release := range releases {
+		plan.Releases = append(plan.Releases, release.GetTagName())
+		plan.Tags = append(plan.Tags, release.GetTagName())
+	}
+
+	m.index.DryRun = m.DryRun
+	if m.DryRun {
+		indexPlan, err := m.index.CleanAllEntries()
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan chart index entries: %w", err)
+		}
+		plan.IndexEntries = indexPlan.IndexEntries
+		m.logger.Info("Dry run: skipping release deletion", "releases", len(plan.Releases))
+		return plan, nil
+	}
+
 	m.logger.Info("Deleting all releases...")
 
 	// Delete all releases from the repository
 	if err := m.ghc.DeleteAllReleases(); err != nil {
-		return fmt.Errorf("failed to delete all releases: %w", err)
+		return nil, fmt.Errorf("failed to delete all releases: %w", err)
 	}
 
 	m.logger.Info("Successfully deleted all releases")
 
 	// Clean up the chart index entries
-	if err := m.index.CleanAllEntries(); err != nil {
-		return fmt.Errorf("failed to clean chart index entries: %w", err)
+	indexPlan, err := m.index.CleanAllEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean chart index entries: %w", err)
 	}
+	plan.IndexEntries = indexPlan.IndexEntries
 	m.logger.Info("Successfully cleaned chart index entries")
 
-	return nil
+	return plan, nil
+}
+
+// deleteReleasesConcurrently deletes every chart from m.Registries (the
+// GitHub release by default, plus any configured OCI registries) using a
+// worker pool bounded by m.Concurrency. Failures are logged per-chart and
+// otherwise ignored, matching the best-effort behavior of the previous
+// serial loop.
+func (m *ReleaseManager) deleteReleasesConcurrently(charts []*ChartInfo) {
+	concurrency := m.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, chart := range charts {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(chart *ChartInfo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, registry := range m.Registries {
+				if err := registry.Delete(chart.Name, chart.Version); err != nil {
+					m.logger.Info("Failed to delete chart from registry", "chart", chart.Name, "version", chart.Version, "error", err)
+				}
+			}
+		}(chart)
+	}
+
+	wg.Wait()
+}
+
+// filterByLabelSelector restricts charts to those whose recorded index
+// annotations match selector.
+func (m *ReleaseManager) filterByLabelSelector(charts []*ChartInfo, selector string) ([]*ChartInfo, error) {
+	matched, err := m.index.ListByLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(matched))
+	for _, c := range matched {
+		allowed[c.Name+"@"+c.Version] = true
+	}
+
+	var filtered []*ChartInfo
+	for _, c := range charts {
+		if allowed[c.Name+"@"+c.Version] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
 }
 
-func (m *ReleaseManager) DeleteChangedCharts() error {
+func (m *ReleaseManager) DeleteChangedCharts() (*CleanupPlan, error) {
 	changedFiles, err := m.git.GetChangedFiles(m.baseBranch, m.chartDir)
 	if err != nil {
-		return fmt.Errorf("failed to get changed files: %w", err)
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
 	if len(changedFiles) == 0 {
 		m.logger.Info("No changed files found since the base branch")
-		return nil
+		return &CleanupPlan{}, nil
 	}
 
-	m.logger.Info("Found %d changed files", len(changedFiles))
+	m.logger.Info("Found changed files", "count", len(changedFiles))
 
 	// Get the charts that match the version pattern
 	changedCharts, err := m.getChangedCharts(changedFiles, m.versionPattern)
 	if err != nil {
-		return fmt.Errorf("failed to get changed charts: %w", err)
+		return nil, fmt.Errorf("failed to get changed charts: %w", err)
 	}
 
 	if len(changedCharts) == 0 {
 		m.logger.Info("No charts found matching the version pattern")
-		return nil
+		return &CleanupPlan{}, nil
 	}
 
-	m.logger.Info("Found %d charts to delete", len(changedCharts))
+	m.logger.Info("Found charts to delete", "count", len(changedCharts))
 
-	// Delete the releases for the changed charts
-	m.logger.Info("Deleting releases for changed charts...")
+	if m.LabelSelector != "" {
+		changedCharts, err = m.filterByLabelSelector(changedCharts, m.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter changed charts by label selector: %w", err)
+		}
+		if len(changedCharts) == 0 {
+			m.logger.Info("No charts matched --label-selector", "selector", m.LabelSelector)
+			return &CleanupPlan{}, nil
+		}
+		m.logger.Info("Restricted to charts matching label selector", "selector", m.LabelSelector, "count", len(changedCharts))
+	}
+
+	if m.KeyringPath != "" {
+		reports, err := m.VerifyReleases(changedCharts, m.KeyringPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify releases before cleanup: %w", err)
+		}
+		if mismatches := FormatMismatches(reports); mismatches != "" {
+			return nil, fmt.Errorf("refusing to delete: release verification found mismatches:\n%s", mismatches)
+		}
+		m.logger.Info("Verified all release signatures and digests before cleanup")
+	}
+
+	plan := &CleanupPlan{}
 	for _, chart := range changedCharts {
 		releaseName := fmt.Sprintf("%s-%s", chart.Name, chart.Version)
-		m.logger.Info("Deleting release: %s", releaseName)
-		if err := m.ghc.DeleteReleaseAndTag(releaseName); err != nil {
-			m.logger.Info("Failed to delete release %s: %v", releaseName, err)
-			continue
+		plan.Releases = append(plan.Releases, releaseName)
+		plan.Tags = append(plan.Tags, releaseName)
+	}
+
+	m.index.DryRun = m.DryRun
+	if m.DryRun {
+		indexPlan, err := m.index.CleanEntriesVersions(changedCharts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan chart index entries: %w", err)
 		}
+		plan.IndexEntries = indexPlan.IndexEntries
+		m.logger.Info("Dry run: skipping release deletion", "releases", len(plan.Releases))
+		return plan, nil
 	}
 
+	// Delete the releases for the changed charts. Network-bound work runs
+	// concurrently, bounded by m.Concurrency; the index mutation below
+	// only ever happens once, after every chart's release has been
+	// deleted, so it never races with these goroutines.
+	m.logger.Info("Deleting releases for changed charts...")
+	m.deleteReleasesConcurrently(changedCharts)
 	m.logger.Info("Successfully deleted all specified chart releases")
 	// Clean up the chart index entries
-	if err := m.index.CleanEntriesVersions(changedCharts); err != nil {
-		return fmt.Errorf("failed to clean chart index entries: %w", err)
+	indexPlan, err := m.index.CleanEntriesVersions(changedCharts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean chart index entries: %w", err)
 	}
+	plan.IndexEntries = indexPlan.IndexEntries
 	m.logger.Info("Successfully cleaned chart index entries")
-	return nil
+	return plan, nil
 }
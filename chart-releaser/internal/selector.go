@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// versionMatcher reports whether a single chart version satisfies one
+// --match constraint.
+type versionMatcher interface {
+	Matches(version string) bool
+}
+
+type semverMatcher struct {
+	constraints *semver.Constraints
+}
+
+func (m semverMatcher) Matches(version string) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return m.constraints.Check(v)
+}
+
+type globMatcher struct {
+	pattern string
+}
+
+func (m globMatcher) Matches(version string) bool {
+	ok, err := filepath.Match(m.pattern, version)
+	return err == nil && ok
+}
+
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) Matches(version string) bool {
+	return m.re.MatchString(version)
+}
+
+// Selector matches charts against per-chart-name constraints supplied via
+// repeated "--match chartName@constraint" flags. constraint is either a
+// semver range (Masterminds/semver/v3, e.g. ">=0.1.0 <0.2.0"), a glob
+// (e.g. "*-dev", "*-rc.*"), or a regex prefixed with "re:".
+type Selector struct {
+	matchers map[string][]versionMatcher
+}
+
+// NewSelector parses match expressions of the form "chartName@constraint".
+func NewSelector(matches []string) (*Selector, error) {
+	s := &Selector{matchers: make(map[string][]versionMatcher)}
+	for _, expr := range matches {
+		name, constraint, ok := strings.Cut(expr, "@")
+		if !ok {
+			return nil, fmt.Errorf("invalid --match expression %q: expected chartName@constraint", expr)
+		}
+
+		m, err := parseVersionMatcher(constraint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint in %q: %w", expr, err)
+		}
+		s.matchers[name] = append(s.matchers[name], m)
+	}
+	return s, nil
+}
+
+func parseVersionMatcher(constraint string) (versionMatcher, error) {
+	switch {
+	case strings.HasPrefix(constraint, "re:"):
+		re, err := regexp.Compile(strings.TrimPrefix(constraint, "re:"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return regexMatcher{re: re}, nil
+	case strings.ContainsAny(constraint, "*?["):
+		return globMatcher{pattern: constraint}, nil
+	default:
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver constraint: %w", err)
+		}
+		return semverMatcher{constraints: c}, nil
+	}
+}
+
+// Matches reports whether chart's version satisfies any constraint
+// registered for its name. A chart with no registered constraints never
+// matches.
+func (s *Selector) Matches(chart ChartInfo) bool {
+	if s == nil {
+		return false
+	}
+	for _, m := range s.matchers[chart.Name] {
+		if m.Matches(chart.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// newestVersions returns the set of version strings among versions that
+// rank among the keepLast newest by semver precedence. Versions that
+// don't parse as semver are excluded from consideration and so are never
+// kept by this safety net. keepLast <= 0 keeps nothing.
+func newestVersions(versions repo.ChartVersions, keepLast int) map[string]bool {
+	kept := make(map[string]bool)
+	if keepLast <= 0 {
+		return kept
+	}
+
+	type parsedVersion struct {
+		version string
+		semver  *semver.Version
+	}
+
+	var parsed []parsedVersion
+	for _, v := range versions {
+		sv, err := semver.NewVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, parsedVersion{version: v.Version, semver: sv})
+	}
+
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].semver.GreaterThan(parsed[j].semver)
+	})
+
+	for i := 0; i < len(parsed) && i < keepLast; i++ {
+		kept[parsed[i].version] = true
+	}
+	return kept
+}
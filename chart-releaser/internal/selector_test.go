@@ -0,0 +1,80 @@
+package internal
+
+import "testing"
+
+func TestSelectorMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		matches []string
+		chart   ChartInfo
+		want    bool
+	}{
+		{
+			name:    "semver range matches",
+			matches: []string{"mychart@>=0.1.0 <0.2.0"},
+			chart:   ChartInfo{Name: "mychart", Version: "0.1.5"},
+			want:    true,
+		},
+		{
+			name:    "semver range does not match",
+			matches: []string{"mychart@>=0.1.0 <0.2.0"},
+			chart:   ChartInfo{Name: "mychart", Version: "0.2.0"},
+			want:    false,
+		},
+		{
+			name:    "glob matches",
+			matches: []string{"mychart@*-dev"},
+			chart:   ChartInfo{Name: "mychart", Version: "0.0.0-dev"},
+			want:    true,
+		},
+		{
+			name:    "regex matches",
+			matches: []string{"mychart@re:^0\\.0\\.0-rc\\.\\d+$"},
+			chart:   ChartInfo{Name: "mychart", Version: "0.0.0-rc.3"},
+			want:    true,
+		},
+		{
+			name:    "regex does not match",
+			matches: []string{"mychart@re:^0\\.0\\.0-rc\\.\\d+$"},
+			chart:   ChartInfo{Name: "mychart", Version: "0.0.0-dev"},
+			want:    false,
+		},
+		{
+			name:    "chart with no registered constraint never matches",
+			matches: []string{"otherchart@>=0.1.0"},
+			chart:   ChartInfo{Name: "mychart", Version: "0.1.0"},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSelector(tt.matches)
+			if err != nil {
+				t.Fatalf("NewSelector(%v) failed: %v", tt.matches, err)
+			}
+			if got := s.Matches(tt.chart); got != tt.want {
+				t.Errorf("Matches(%+v) = %v, want %v", tt.chart, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatchesNilSelector(t *testing.T) {
+	var s *Selector
+	if s.Matches(ChartInfo{Name: "mychart", Version: "0.1.0"}) {
+		t.Error("nil Selector should never match")
+	}
+}
+
+func TestNewSelectorInvalidExpression(t *testing.T) {
+	if _, err := NewSelector([]string{"mychart-missing-at-sign"}); err == nil {
+		t.Error("expected an error for a --match expression without '@'")
+	}
+}
+
+func TestNewSelectorInvalidConstraint(t *testing.T) {
+	if _, err := NewSelector([]string{"mychart@not a valid constraint!!"}); err == nil {
+		t.Error("expected an error for an invalid semver constraint")
+	}
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// VerificationReport describes the result of verifying one release's
+// ".tgz"/".tgz.prov" assets against a GPG keyring and the digest recorded
+// for it in index.yaml, as produced by ReleaseManager.VerifyReleases.
+type VerificationReport struct {
+	Name        string
+	Version     string
+	SignatureOK bool
+	DigestOK    bool
+	Err         error
+}
+
+// Mismatched reports whether this release failed signature or digest
+// verification and is therefore not safe to delete.
+func (r VerificationReport) Mismatched() bool {
+	return r.Err != nil || !r.SignatureOK || !r.DigestOK
+}
+
+// String renders a one-line summary suitable for a cleanup-abort report.
+func (r VerificationReport) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s-%s: %v", r.Name, r.Version, r.Err)
+	}
+	return fmt.Sprintf("%s-%s: signatureOK=%t digestOK=%t", r.Name, r.Version, r.SignatureOK, r.DigestOK)
+}
+
+// VerifyReleases downloads each of charts' ".tgz" and ".tgz.prov" release
+// assets, validates the PGP signature against keyringPath (the same check
+// `helm verify` performs), recomputes the chart's SHA-256 digest, and
+// cross-checks it against the "digest:" field recorded for that version
+// in index.yaml. It performs no deletions; callers should abort cleanup
+// if any returned report is Mismatched.
+func (m *ReleaseManager) VerifyReleases(charts []*ChartInfo, keyringPath string) ([]VerificationReport, error) {
+	indexFile, err := m.index.LoadIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index: %w", err)
+	}
+
+	signatory, err := provenance.NewFromKeyring(keyringPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keyring %s: %w", keyringPath, err)
+	}
+
+	reports := make([]VerificationReport, 0, len(charts))
+	for _, chart := range charts {
+		digest := indexDigest(indexFile, chart.Name, chart.Version)
+		reports = append(reports, m.verifyRelease(signatory, chart.Name, chart.Version, digest))
+	}
+	return reports, nil
+}
+
+func indexDigest(indexFile *repo.IndexFile, name, version string) string {
+	for _, v := range indexFile.Entries[name] {
+		if v.Version == version {
+			return v.Digest
+		}
+	}
+	return ""
+}
+
+func (m *ReleaseManager) verifyRelease(signatory *provenance.Signatory, name, version, digest string) VerificationReport {
+	report := VerificationReport{Name: name, Version: version}
+
+	releaseName := fmt.Sprintf("%s-%s", name, version)
+	archiveName := releaseName + ".tgz"
+	provName := archiveName + ".prov"
+
+	archive, err := m.ghc.DownloadReleaseAsset(releaseName, archiveName)
+	if err != nil {
+		report.Err = fmt.Errorf("failed to download %s: %w", archiveName, err)
+		return report
+	}
+
+	prov, err := m.ghc.DownloadReleaseAsset(releaseName, provName)
+	if err != nil {
+		report.Err = fmt.Errorf("failed to download %s: %w", provName, err)
+		return report
+	}
+
+	dir, err := os.MkdirTemp("", "chart-release-verify-")
+	if err != nil {
+		report.Err = fmt.Errorf("failed to create temp dir: %w", err)
+		return report
+	}
+	defer os.RemoveAll(dir)
+
+	archivePath := filepath.Join(dir, archiveName)
+	provPath := filepath.Join(dir, provName)
+	if err := os.WriteFile(archivePath, archive, 0644); err != nil {
+		report.Err = fmt.Errorf("failed to write %s: %w", archiveName, err)
+		return report
+	}
+	if err := os.WriteFile(provPath, prov, 0644); err != nil {
+		report.Err = fmt.Errorf("failed to write %s: %w", provName, err)
+		return report
+	}
+
+	if _, err := signatory.Verify(archivePath, provPath); err != nil {
+		report.Err = fmt.Errorf("signature verification failed: %w", err)
+		return report
+	}
+	report.SignatureOK = true
+
+	sum := sha256.Sum256(archive)
+	report.DigestOK = digest == "" || hex.EncodeToString(sum[:]) == digest
+
+	return report
+}
+
+// FormatMismatches renders a diff-style report of every mismatched
+// release, for the error DeleteChangedCharts returns when it aborts.
+func FormatMismatches(reports []VerificationReport) string {
+	var lines []string
+	for _, r := range reports {
+		if r.Mismatched() {
+			lines = append(lines, "  - "+r.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
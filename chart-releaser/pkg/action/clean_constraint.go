@@ -0,0 +1,42 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// CleanByConstraint removes every version of a single chart satisfying a
+// SemVer constraint. It corresponds to `chart-release-manager cleanup
+// --chart --constraint`.
+type CleanByConstraint struct {
+	cfg *Configuration
+
+	ChartName  string
+	Constraint string
+
+	// DryRun, when true, returns the CleanupPlan without mutating the
+	// index.
+	DryRun bool
+
+	// Force skips the index's digest verification before deleting a
+	// version.
+	Force bool
+}
+
+// NewCleanByConstraint creates a new CleanByConstraint action bound to cfg.
+func NewCleanByConstraint(cfg *Configuration, chartName, constraint string) *CleanByConstraint {
+	return &CleanByConstraint{cfg: cfg, ChartName: chartName, Constraint: constraint}
+}
+
+// Run removes every version of ChartName matching Constraint.
+func (a *CleanByConstraint) Run() (*internal.CleanupPlan, error) {
+	a.cfg.Index.DryRun = a.DryRun
+	a.cfg.Index.Force = a.Force
+
+	plan, err := a.cfg.Index.CleanEntriesByConstraint(a.ChartName, a.Constraint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean %s entries matching %s: %w", a.ChartName, a.Constraint, err)
+	}
+	return plan, nil
+}
@@ -0,0 +1,33 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// CleanIndex removes entries from the chart index without touching GitHub
+// releases. It corresponds to `chart-release-manager cleanup --all`.
+type CleanIndex struct {
+	cfg *Configuration
+
+	// DryRun, when true, returns the CleanupPlan without mutating the
+	// index.
+	DryRun bool
+}
+
+// NewCleanIndex creates a new CleanIndex action bound to cfg.
+func NewCleanIndex(cfg *Configuration) *CleanIndex {
+	return &CleanIndex{cfg: cfg}
+}
+
+// Run clears every entry from index.yaml on the pages branch.
+func (a *CleanIndex) Run() (*internal.CleanupPlan, error) {
+	a.cfg.Index.DryRun = a.DryRun
+
+	plan, err := a.cfg.Index.CleanAllEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean all chart index entries: %w", err)
+	}
+	return plan, nil
+}
@@ -0,0 +1,48 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// CleanMatchingVersions removes index entries selected by one or more
+// "chartName@constraint" expressions, independent of git history. It
+// corresponds to `chart-release-manager cleanup --match`.
+type CleanMatchingVersions struct {
+	cfg *Configuration
+
+	Match    []string
+	KeepLast int
+
+	// DryRun, when true, returns the CleanupPlan without mutating the
+	// index.
+	DryRun bool
+
+	// Force skips the index's digest verification before deleting a
+	// version.
+	Force bool
+}
+
+// NewCleanMatchingVersions creates a new CleanMatchingVersions action bound to cfg.
+func NewCleanMatchingVersions(cfg *Configuration, match []string, keepLast int) *CleanMatchingVersions {
+	return &CleanMatchingVersions{cfg: cfg, Match: match, KeepLast: keepLast}
+}
+
+// Run removes every index entry matched by Match, keeping the newest
+// KeepLast semver versions of each chart regardless of match.
+func (a *CleanMatchingVersions) Run() (*internal.CleanupPlan, error) {
+	selector, err := internal.NewSelector(a.Match)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --match expressions: %w", err)
+	}
+
+	a.cfg.Index.DryRun = a.DryRun
+	a.cfg.Index.Force = a.Force
+
+	plan, err := a.cfg.Index.CleanMatchingEntries(selector, a.KeepLast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean matching chart index entries: %w", err)
+	}
+	return plan, nil
+}
@@ -0,0 +1,46 @@
+// Package action exposes the chart-releaser's cleanup and release-management
+// logic as typed, reusable actions, mirroring the split Helm's own CLI uses
+// between cmd/helm and pkg/action. Programs that want to embed the releaser
+// (CI operators, controllers) can construct a Configuration and drive the
+// action structs directly instead of shelling out to the cobra binary.
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// Configuration holds the shared clients every action needs. It is built
+// once per invocation and passed by reference into each action struct, the
+// same way helm's action.Configuration is shared across action.Install,
+// action.Upgrade, etc.
+type Configuration struct {
+	Git      *internal.Git
+	GHClient *internal.GHClient
+	Index    *internal.IndexManager
+}
+
+// Init constructs the Git, GitHub and index clients and stores them on the
+// Configuration. It is the programmatic equivalent of the flag parsing that
+// used to live directly in cmd/main.go. backend is passed straight through
+// to internal.NewIndexManager, so it accepts a plain pages branch name, a
+// "git+https://...#branch" URL, or an "oci://host/repository" URL.
+func (c *Configuration) Init(chartDir, backend, owner, repo, ghToken string) error {
+	ghc, err := internal.NewGHClient(owner, repo, ghToken)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Rooted at the repository root, not chartDir: internal.Git opens the
+	// repository with gogit.PlainOpen, which (unlike the git binary) does
+	// not search parent directories for .git, so opening it at a chart
+	// subdirectory like "charts" fails outright.
+	git := internal.NewGit(".")
+
+	c.Git = git
+	c.GHClient = ghc
+	c.Index = internal.NewIndexManager(git, backend)
+
+	return nil
+}
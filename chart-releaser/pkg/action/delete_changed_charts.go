@@ -0,0 +1,86 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// DeleteChangedCharts deletes releases (and their index entries) for charts
+// whose files changed since BaseBranch (or that locally depend on such a
+// chart) and whose version matches VersionPattern. It corresponds to
+// `chart-release-manager cleanup`.
+type DeleteChangedCharts struct {
+	cfg *Configuration
+
+	BaseBranch     string
+	ChartDir       string
+	VersionPattern string
+
+	// DryRun, when true, returns the CleanupPlan without deleting
+	// anything.
+	DryRun bool
+
+	// Concurrency bounds how many chart releases are deleted in parallel.
+	// Zero leaves the ReleaseManager/GHClient defaults in place.
+	Concurrency int
+
+	// Force skips the index's digest verification before deleting a
+	// version.
+	Force bool
+
+	// Registries are additional "oci://host/repository" registries to
+	// also delete each removed chart version's artifact from, alongside
+	// the GitHub release.
+	Registries []string
+
+	// KeyringPath, when set, verifies every changed chart's signature and
+	// digest before deleting anything; see ReleaseManager.VerifyReleases.
+	KeyringPath string
+
+	// LabelSelector, when set, restricts deletion to changed charts whose
+	// index entry matches this Kubernetes-style label selector.
+	LabelSelector string
+}
+
+// NewDeleteChangedCharts creates a new DeleteChangedCharts action bound to cfg.
+func NewDeleteChangedCharts(cfg *Configuration, baseBranch, chartDir, versionPattern string) *DeleteChangedCharts {
+	return &DeleteChangedCharts{
+		cfg:            cfg,
+		BaseBranch:     baseBranch,
+		ChartDir:       chartDir,
+		VersionPattern: versionPattern,
+	}
+}
+
+// Run computes the changed charts and deletes their releases and index
+// entries.
+func (a *DeleteChangedCharts) Run() (*internal.CleanupPlan, error) {
+	releaseManager := internal.NewReleaseManager(
+		a.BaseBranch,
+		a.ChartDir,
+		a.cfg.Git,
+		a.cfg.Index,
+		a.cfg.GHClient,
+		a.VersionPattern,
+	)
+	releaseManager.DryRun = a.DryRun
+	releaseManager.KeyringPath = a.KeyringPath
+	releaseManager.LabelSelector = a.LabelSelector
+	a.cfg.Index.Force = a.Force
+	if a.Concurrency > 0 {
+		releaseManager.Concurrency = a.Concurrency
+		a.cfg.GHClient.SetConcurrency(a.Concurrency)
+	}
+	for _, registry := range a.Registries {
+		releaseManager.Registries = append(releaseManager.Registries, internal.NewOCIRegistry(strings.TrimPrefix(registry, "oci://")))
+	}
+
+	plan, err := releaseManager.DeleteChangedCharts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete changed chart releases: %w", err)
+	}
+
+	return plan, nil
+}
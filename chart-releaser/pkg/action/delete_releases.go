@@ -0,0 +1,35 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// DeleteReleases deletes every GitHub release for the configured repository
+// and clears the corresponding chart index entries. It corresponds to the
+// legacy `delete-all` behavior.
+type DeleteReleases struct {
+	cfg *Configuration
+
+	// DryRun, when true, returns the CleanupPlan without deleting
+	// anything.
+	DryRun bool
+}
+
+// NewDeleteReleases creates a new DeleteReleases action bound to cfg.
+func NewDeleteReleases(cfg *Configuration) *DeleteReleases {
+	return &DeleteReleases{cfg: cfg}
+}
+
+// Run deletes all releases and cleans the chart index.
+func (a *DeleteReleases) Run() (*internal.CleanupPlan, error) {
+	releaseManager := internal.NewReleaseManager("", "", a.cfg.Git, a.cfg.Index, a.cfg.GHClient, "")
+	releaseManager.DryRun = a.DryRun
+
+	plan, err := releaseManager.DeleteAllReleases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete all releases: %w", err)
+	}
+	return plan, nil
+}
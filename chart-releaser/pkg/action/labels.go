@@ -0,0 +1,76 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// SetLabels sets labels (annotations) on a single chart index entry. It
+// corresponds to `chart-release-manager labels set`.
+type SetLabels struct {
+	cfg *Configuration
+
+	ChartName string
+	Version   string
+	Labels    map[string]string
+}
+
+// NewSetLabels creates a new SetLabels action bound to cfg.
+func NewSetLabels(cfg *Configuration, chartName, version string, labels map[string]string) *SetLabels {
+	return &SetLabels{cfg: cfg, ChartName: chartName, Version: version, Labels: labels}
+}
+
+// Run merges Labels into ChartName@Version's recorded annotations.
+func (a *SetLabels) Run() error {
+	if err := a.cfg.Index.SetLabels(a.ChartName, a.Version, a.Labels); err != nil {
+		return fmt.Errorf("failed to set labels on %s@%s: %w", a.ChartName, a.Version, err)
+	}
+	return nil
+}
+
+// RemoveLabels removes labels (annotations) from a single chart index
+// entry. It corresponds to `chart-release-manager labels remove`.
+type RemoveLabels struct {
+	cfg *Configuration
+
+	ChartName string
+	Version   string
+	Keys      []string
+}
+
+// NewRemoveLabels creates a new RemoveLabels action bound to cfg.
+func NewRemoveLabels(cfg *Configuration, chartName, version string, keys []string) *RemoveLabels {
+	return &RemoveLabels{cfg: cfg, ChartName: chartName, Version: version, Keys: keys}
+}
+
+// Run removes Keys from ChartName@Version's recorded annotations, or every
+// annotation if Keys is empty.
+func (a *RemoveLabels) Run() error {
+	if err := a.cfg.Index.RemoveLabels(a.ChartName, a.Version, a.Keys...); err != nil {
+		return fmt.Errorf("failed to remove labels from %s@%s: %w", a.ChartName, a.Version, err)
+	}
+	return nil
+}
+
+// ListLabels lists every chart index entry matching a Kubernetes-style
+// label selector. It corresponds to `chart-release-manager labels list`.
+type ListLabels struct {
+	cfg *Configuration
+
+	Selector string
+}
+
+// NewListLabels creates a new ListLabels action bound to cfg.
+func NewListLabels(cfg *Configuration, selector string) *ListLabels {
+	return &ListLabels{cfg: cfg, Selector: selector}
+}
+
+// Run returns every chart@version whose index entry matches Selector.
+func (a *ListLabels) Run() ([]*internal.ChartInfo, error) {
+	matched, err := a.cfg.Index.ListByLabelSelector(a.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chart index entries matching %q: %w", a.Selector, err)
+	}
+	return matched, nil
+}
@@ -0,0 +1,40 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/zncdatadev/kubedoop-helm-charts/chart-releaser/internal"
+)
+
+// PruneVersions keeps only the newest KeepN SemVer versions of each chart
+// in the index. It corresponds to `chart-release-manager cleanup --prune-keep`.
+type PruneVersions struct {
+	cfg *Configuration
+
+	KeepN int
+
+	// DryRun, when true, returns the CleanupPlan without mutating the
+	// index.
+	DryRun bool
+
+	// Force skips the index's digest verification before deleting a
+	// version.
+	Force bool
+}
+
+// NewPruneVersions creates a new PruneVersions action bound to cfg.
+func NewPruneVersions(cfg *Configuration, keepN int) *PruneVersions {
+	return &PruneVersions{cfg: cfg, KeepN: keepN}
+}
+
+// Run prunes every chart in the index down to its newest KeepN versions.
+func (a *PruneVersions) Run() (*internal.CleanupPlan, error) {
+	a.cfg.Index.DryRun = a.DryRun
+	a.cfg.Index.Force = a.Force
+
+	plan, err := a.cfg.Index.PruneOldVersions(a.KeepN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune old chart versions: %w", err)
+	}
+	return plan, nil
+}
@@ -1,20 +1,65 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 )
 
 // ChartMetadata represents the Chart.yaml structure
 type ChartMetadata struct {
-	Name    string `yaml:"name"`
-	Version string `yaml:"version"`
+	APIVersion   string            `yaml:"apiVersion"`
+	Name         string            `yaml:"name"`
+	Version      string            `yaml:"version"`
+	Dependencies []ChartDependency `yaml:"dependencies"`
+}
+
+// ChartDependency is a single entry of Chart.yaml's "dependencies:" block
+// (or, for apiVersion v1 charts, of requirements.yaml).
+type ChartDependency struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Repository string `yaml:"repository"`
+}
+
+// requirementsFile is the apiVersion v1 equivalent of Chart.yaml's
+// "dependencies:" block, as consumed by Helm 2 and still read by fluxcd's
+// LoadChartMetadata for legacy charts.
+type requirementsFile struct {
+	Dependencies []ChartDependency `yaml:"dependencies"`
+}
+
+// loadDependencies returns chart's dependency list, falling back to
+// requirements.yaml for apiVersion v1 charts, which don't carry
+// "dependencies:" in Chart.yaml itself.
+func loadDependencies(chartPath, apiVersion string, dependencies []ChartDependency) ([]ChartDependency, error) {
+	if apiVersion != "v1" {
+		return dependencies, nil
+	}
+
+	reqFile := filepath.Join(chartPath, "requirements.yaml")
+	data, err := os.ReadFile(reqFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", reqFile, err)
+	}
+
+	var req requirementsFile
+	if err := yaml.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", reqFile, err)
+	}
+	return req.Dependencies, nil
 }
 
 // ChartInfo represents information about a Helm chart
@@ -75,10 +120,191 @@ func (cm *ChartManager) VersionMatchesPattern(version, pattern string) (bool, er
 	return regex.MatchString(version), nil
 }
 
-// GetChangedCharts gets list of changed charts that match the version pattern
-func (cm *ChartManager) GetChangedCharts(changedFiles []string, versionPattern string) ([]*ChartInfo, error) {
+// VersionFilter matches a chart version against either a SemVer
+// constraint (e.g. ">=1.2.0 <2.0.0-0", "~1.4", "^0.5") or, if pattern
+// doesn't parse as one, a regular expression - so GetChangedCharts can
+// accept both without callers having to say which they mean.
+type VersionFilter struct {
+	constraint *semver.Constraints
+	regex      *regexp.Regexp
+}
+
+// NewVersionFilter parses pattern as a SemVer constraint first, falling
+// back to a regular expression.
+func NewVersionFilter(pattern string) (*VersionFilter, error) {
+	if c, err := semver.NewConstraint(pattern); err == nil {
+		return &VersionFilter{constraint: c}, nil
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern '%s': not a valid SemVer constraint or regex: %w", pattern, err)
+	}
+	return &VersionFilter{regex: regex}, nil
+}
+
+// Matches reports whether version satisfies the filter.
+func (f *VersionFilter) Matches(version string) (bool, error) {
+	if f.constraint != nil {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			return false, fmt.Errorf("invalid SemVer version '%s': %w", version, err)
+		}
+		return f.constraint.Check(v), nil
+	}
+	return f.regex.MatchString(version), nil
+}
+
+// SemverBump classifies how a chart's version changed between two
+// revisions, as returned by ChartManager.CompareVersions.
+type SemverBump string
+
+const (
+	SemverBumpMajor      SemverBump = "major"
+	SemverBumpMinor      SemverBump = "minor"
+	SemverBumpPatch      SemverBump = "patch"
+	SemverBumpPrerelease SemverBump = "prerelease"
+	SemverBumpBuild      SemverBump = "build"
+	SemverBumpNone       SemverBump = "none"
+	SemverBumpInvalid    SemverBump = "invalid"
+)
+
+// CompareVersions classifies the version change of the chart at oldPath
+// (e.g. a worktree checked out at the base ref) versus newPath (e.g. the
+// current checkout), by reading each directory's Chart.yaml and comparing
+// with Masterminds/semver/v3. Either version failing to parse as SemVer
+// yields SemverBumpInvalid rather than an error, so callers can report it
+// alongside real bumps instead of aborting.
+func (cm *ChartManager) CompareVersions(oldPath, newPath string) (SemverBump, error) {
+	oldInfo, err := cm.GetChartInfo(oldPath)
+	if err != nil {
+		return SemverBumpInvalid, fmt.Errorf("failed to read old chart info from %s: %w", oldPath, err)
+	}
+	newInfo, err := cm.GetChartInfo(newPath)
+	if err != nil {
+		return SemverBumpInvalid, fmt.Errorf("failed to read new chart info from %s: %w", newPath, err)
+	}
+
+	oldVer, err := semver.NewVersion(oldInfo.Version)
+	if err != nil {
+		return SemverBumpInvalid, nil
+	}
+	newVer, err := semver.NewVersion(newInfo.Version)
+	if err != nil {
+		return SemverBumpInvalid, nil
+	}
+
+	switch {
+	case newVer.Major() != oldVer.Major():
+		return SemverBumpMajor, nil
+	case newVer.Minor() != oldVer.Minor():
+		return SemverBumpMinor, nil
+	case newVer.Patch() != oldVer.Patch():
+		return SemverBumpPatch, nil
+	case newVer.Prerelease() != oldVer.Prerelease():
+		return SemverBumpPrerelease, nil
+	case newVer.Metadata() != oldVer.Metadata():
+		return SemverBumpBuild, nil
+	default:
+		return SemverBumpNone, nil
+	}
+}
+
+// listAllCharts scans every Helm chart directly under cm.ChartsDir and
+// returns a map from chart name to its ChartInfo.
+func (cm *ChartManager) listAllCharts() (map[string]*ChartInfo, error) {
+	entries, err := os.ReadDir(cm.ChartsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read charts dir %s: %w", cm.ChartsDir, err)
+	}
+
+	charts := make(map[string]*ChartInfo)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		chartPath := filepath.Join(cm.ChartsDir, entry.Name())
+		if !cm.IsHelmChart(chartPath) {
+			continue
+		}
+
+		chartInfo, err := cm.GetChartInfo(chartPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart info for %s: %w", chartPath, err)
+		}
+		charts[chartInfo.Name] = chartInfo
+	}
+
+	return charts, nil
+}
+
+// BuildDependencyGraph scans every Helm chart under cm.ChartsDir and
+// returns a map from chart name to the names of its local ("file://")
+// dependencies, resolved to sibling chart directories. Dependencies on
+// remote repositories are ignored, since only local charts can affect
+// change detection.
+func (cm *ChartManager) BuildDependencyGraph() (map[string][]string, error) {
+	charts, err := cm.listAllCharts()
+	if err != nil {
+		return nil, err
+	}
+
+	chartsByPath := make(map[string]*ChartInfo, len(charts))
+	for _, chart := range charts {
+		chartsByPath[filepath.Clean(chart.Path)] = chart
+	}
+
+	graph := make(map[string][]string, len(charts))
+	for _, chart := range charts {
+		data, err := os.ReadFile(filepath.Join(chart.Path, "Chart.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart file for %s: %w", chart.Name, err)
+		}
+
+		var metadata ChartMetadata
+		if err := yaml.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse chart file for %s: %w", chart.Name, err)
+		}
+
+		dependencies, err := loadDependencies(chart.Path, metadata.APIVersion, metadata.Dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dependencies for %s: %w", chart.Name, err)
+		}
+
+		var localDeps []string
+		for _, dep := range dependencies {
+			if !strings.HasPrefix(dep.Repository, "file://") {
+				continue
+			}
+
+			depPath := filepath.Clean(filepath.Join(chart.Path, strings.TrimPrefix(dep.Repository, "file://")))
+			depChart, ok := chartsByPath[depPath]
+			if !ok {
+				return nil, fmt.Errorf("chart %s depends on %s, but no chart was found at %s", chart.Name, dep.Repository, depPath)
+			}
+			localDeps = append(localDeps, depChart.Name)
+		}
+
+		graph[chart.Name] = localDeps
+	}
+
+	return graph, nil
+}
+
+// GetChangedCharts gets list of changed charts that match the version
+// pattern. When expandDependents is true, every parent chart that locally
+// depends (directly or transitively), per BuildDependencyGraph, on a
+// directly changed chart is also included, so a subchart bump flips its
+// parents too; pass false to keep the old, directory-only behavior.
+func (cm *ChartManager) GetChangedCharts(changedFiles []string, versionPattern string, expandDependents bool) ([]*ChartInfo, error) {
 	var changedCharts []*ChartInfo
 
+	filter, err := NewVersionFilter(versionPattern)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract unique chart directories from changed files
 	chartDirs := make(map[string]bool)
 	for _, filePath := range changedFiles {
@@ -89,7 +315,13 @@ func (cm *ChartManager) GetChangedCharts(changedFiles []string, versionPattern s
 		}
 	}
 
-	// Filter and validate charts
+	allCharts, err := cm.listAllCharts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list charts: %w", err)
+	}
+
+	// Determine the direct set of changed chart names.
+	changedNames := make(map[string]bool)
 	for chartDir := range chartDirs {
 		if !cm.IsHelmChart(chartDir) {
 			log.Printf("%s is not a Helm chart. Skipping.", chartDir)
@@ -102,17 +334,93 @@ func (cm *ChartManager) GetChangedCharts(changedFiles []string, versionPattern s
 			continue
 		}
 
-		matches, err := cm.VersionMatchesPattern(chartInfo.Version, versionPattern)
+		changedNames[chartInfo.Name] = true
+	}
+
+	if expandDependents {
+		graph, err := cm.BuildDependencyGraph()
 		if err != nil {
-			return nil, fmt.Errorf("error checking version pattern: %w", err)
+			return nil, fmt.Errorf("failed to build chart dependency graph: %w", err)
 		}
 
-		if !matches {
-			return nil, fmt.Errorf("chart version %s does not match supported pattern for deletion: %s", chartInfo.Version, versionPattern)
+		dependents := make(map[string][]string)
+		for name, deps := range graph {
+			for _, dep := range deps {
+				dependents[dep] = append(dependents[dep], name)
+			}
 		}
 
-		log.Printf("Found Helm chart: %s with version %s", chartDir, chartInfo.Version)
-		changedCharts = append(changedCharts, chartInfo)
+		queue := make([]string, 0, len(changedNames))
+		for name := range changedNames {
+			queue = append(queue, name)
+		}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for _, dependent := range dependents[name] {
+				if changedNames[dependent] {
+					continue
+				}
+				changedNames[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	// Dedup by (Name, Version) before validating, so a chart reachable
+	// through more than one changed path - e.g. a rename reported twice by
+	// `git diff --find-renames`, or an umbrella chart pulled in both
+	// directly and via dependency expansion - is pattern-checked exactly
+	// once.
+	uniqueCharts := make(map[string]*ChartInfo)
+	for name := range changedNames {
+		chartInfo, ok := allCharts[name]
+		if !ok {
+			return nil, fmt.Errorf("chart %s is referenced as changed but was not found under %s", name, cm.ChartsDir)
+		}
+
+		key := chartInfo.Name + "@" + chartInfo.Version
+		uniqueCharts[key] = chartInfo
+	}
+
+	// Validate each unique chart's version against versionPattern across a
+	// bounded worker pool, collecting every mismatch instead of bailing on
+	// the first one.
+	var (
+		mu   sync.Mutex
+		errs []error
+		g    errgroup.Group
+	)
+	g.SetLimit(runtime.NumCPU())
+
+	for _, chartInfo := range uniqueCharts {
+		chartInfo := chartInfo
+		g.Go(func() error {
+			matches, err := filter.Matches(chartInfo.Version)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("error checking version pattern for %s: %w", chartInfo.Name, err))
+				mu.Unlock()
+				return nil
+			}
+			if !matches {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("chart %s version %s does not match supported pattern for deletion: %s", chartInfo.Name, chartInfo.Version, versionPattern))
+				mu.Unlock()
+				return nil
+			}
+
+			log.Printf("Found Helm chart: %s with version %s", chartInfo.Name, chartInfo.Version)
+			mu.Lock()
+			changedCharts = append(changedCharts, chartInfo)
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
 	return changedCharts, nil
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// chdir changes the test process's working directory to dir and restores
+// it when t ends. testing.T.Chdir only exists from Go 1.24, and nothing
+// in this repo pins a minimum Go version, so tests use this instead.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to chdir to %s: %v", dir, err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(old); err != nil {
+			t.Fatalf("failed to restore working directory: %v", err)
+		}
+	})
+}
+
+// writeChart creates chartsDir/name/Chart.yaml with the given version and,
+// if deps is non-empty, a "dependencies:" block listing them.
+func writeChart(t *testing.T, chartsDir, name, version string, deps []ChartDependency) string {
+	t.Helper()
+
+	chartDir := filepath.Join(chartsDir, name)
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatalf("failed to create chart dir %s: %v", chartDir, err)
+	}
+
+	content := fmt.Sprintf("apiVersion: v2\nname: %s\nversion: %s\n", name, version)
+	if len(deps) > 0 {
+		content += "dependencies:\n"
+		for _, dep := range deps {
+			content += fmt.Sprintf("  - name: %s\n    version: %s\n    repository: %s\n", dep.Name, dep.Version, dep.Repository)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write Chart.yaml for %s: %v", name, err)
+	}
+	return chartDir
+}
+
+func TestVersionFilterMatchesSemverConstraintOrRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		version string
+		want    bool
+	}{
+		{name: "semver constraint matches", pattern: ">=1.0.0 <2.0.0", version: "1.2.3", want: true},
+		{name: "semver constraint rejects", pattern: ">=2.0.0", version: "1.2.3", want: false},
+		{name: "regex fallback matches", pattern: "^1\\..+", version: "1.2.3", want: true},
+		{name: "regex fallback rejects", pattern: "^2\\..+", version: "1.2.3", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewVersionFilter(tt.pattern)
+			if err != nil {
+				t.Fatalf("NewVersionFilter failed: %v", err)
+			}
+			got, err := filter.Matches(tt.version)
+			if err != nil {
+				t.Fatalf("Matches failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Matches(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartManagerCompareVersionsClassifiesBump(t *testing.T) {
+	chartsDir := t.TempDir()
+	oldPath := writeChart(t, chartsDir, "old", "1.2.3", nil)
+	cm := NewChartManager(chartsDir)
+
+	tests := []struct {
+		name       string
+		newVersion string
+		want       SemverBump
+	}{
+		{name: "major bump", newVersion: "2.0.0", want: SemverBumpMajor},
+		{name: "minor bump", newVersion: "1.3.0", want: SemverBumpMinor},
+		{name: "patch bump", newVersion: "1.2.4", want: SemverBumpPatch},
+		{name: "no change", newVersion: "1.2.3", want: SemverBumpNone},
+		{name: "invalid semver", newVersion: "not-a-version", want: SemverBumpInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newPath := writeChart(t, chartsDir, "old-"+tt.name, tt.newVersion, nil)
+			bump, err := cm.CompareVersions(oldPath, newPath)
+			if err != nil {
+				t.Fatalf("CompareVersions failed: %v", err)
+			}
+			if bump != tt.want {
+				t.Errorf("CompareVersions(%s, %s) = %q, want %q", oldPath, newPath, bump, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartManagerBuildDependencyGraphResolvesLocalDependencies(t *testing.T) {
+	chartsDir := t.TempDir()
+	writeChart(t, chartsDir, "util", "1.0.0", nil)
+	writeChart(t, chartsDir, "lib", "1.0.0", []ChartDependency{
+		{Name: "util", Version: "1.0.0", Repository: "file://../util"},
+	})
+	writeChart(t, chartsDir, "app", "1.0.0", []ChartDependency{
+		{Name: "lib", Version: "1.0.0", Repository: "file://../lib"},
+		{Name: "remote", Version: "1.0.0", Repository: "https://example.com/charts"},
+	})
+
+	cm := NewChartManager(chartsDir)
+	graph, err := cm.BuildDependencyGraph()
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph failed: %v", err)
+	}
+
+	if got := graph["app"]; len(got) != 1 || got[0] != "lib" {
+		t.Errorf("expected app to depend only on lib, got %v", got)
+	}
+	if got := graph["lib"]; len(got) != 1 || got[0] != "util" {
+		t.Errorf("expected lib to depend only on util, got %v", got)
+	}
+	if got := graph["util"]; len(got) != 0 {
+		t.Errorf("expected util to have no local dependencies, got %v", got)
+	}
+}
+
+// TestChartManagerGetChangedChartsConcurrentValidation exercises
+// GetChangedCharts' bounded worker pool across many charts at once, so
+// `go test -race` can catch a regression in the mutex guarding errs and
+// changedCharts.
+func TestChartManagerGetChangedChartsConcurrentValidation(t *testing.T) {
+	chdir(t, t.TempDir())
+	const chartsDir = "charts"
+
+	const numCharts = 50
+	var changedFiles []string
+	for i := 0; i < numCharts; i++ {
+		name := fmt.Sprintf("chart%d", i)
+		writeChart(t, chartsDir, name, "1.0.0", nil)
+		changedFiles = append(changedFiles, filepath.Join(chartsDir, name, "Chart.yaml"))
+	}
+
+	cm := NewChartManager(chartsDir)
+	charts, err := cm.GetChangedCharts(changedFiles, ">=1.0.0", false)
+	if err != nil {
+		t.Fatalf("GetChangedCharts failed: %v", err)
+	}
+	if len(charts) != numCharts {
+		t.Errorf("expected %d changed charts, got %d", numCharts, len(charts))
+	}
+}
+
+func TestChartManagerGetChangedChartsExpandsDependents(t *testing.T) {
+	chdir(t, t.TempDir())
+	const chartsDir = "charts"
+
+	writeChart(t, chartsDir, "util", "1.0.0", nil)
+	writeChart(t, chartsDir, "lib", "1.0.0", []ChartDependency{
+		{Name: "util", Version: "1.0.0", Repository: "file://../util"},
+	})
+	writeChart(t, chartsDir, "app", "1.0.0", []ChartDependency{
+		{Name: "lib", Version: "1.0.0", Repository: "file://../lib"},
+	})
+
+	cm := NewChartManager(chartsDir)
+	changedFiles := []string{filepath.Join(chartsDir, "util", "Chart.yaml")}
+
+	charts, err := cm.GetChangedCharts(changedFiles, ">=1.0.0", true)
+	if err != nil {
+		t.Fatalf("GetChangedCharts failed: %v", err)
+	}
+
+	names := make(map[string]bool, len(charts))
+	for _, c := range charts {
+		names[c.Name] = true
+	}
+	for _, want := range []string{"util", "lib", "app"} {
+		if !names[want] {
+			t.Errorf("expected GetChangedCharts to include %s, got %v", want, names)
+		}
+	}
+}
+
+func TestChartManagerGetChangedChartsReportsAllVersionMismatches(t *testing.T) {
+	chdir(t, t.TempDir())
+	const chartsDir = "charts"
+
+	writeChart(t, chartsDir, "bad1", "1.0.0", nil)
+	writeChart(t, chartsDir, "bad2", "1.0.0", nil)
+
+	cm := NewChartManager(chartsDir)
+	changedFiles := []string{
+		filepath.Join(chartsDir, "bad1", "Chart.yaml"),
+		filepath.Join(chartsDir, "bad2", "Chart.yaml"),
+	}
+
+	_, err := cm.GetChangedCharts(changedFiles, ">=2.0.0", false)
+	if err == nil {
+		t.Fatal("expected an error for charts that don't match the version pattern")
+	}
+	if !strings.Contains(err.Error(), "bad1") || !strings.Contains(err.Error(), "bad2") {
+		t.Errorf("expected the joined error to mention both mismatched charts, got: %v", err)
+	}
+}
@@ -3,42 +3,96 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 )
 
-// Git manages Git operations for detecting changes and managing branches
+// GitBackend is the set of Git operations needed by the release manager,
+// abstracted so the underlying implementation - shelling out to the git
+// binary, or driving go-git in-process - can be swapped independently of
+// the callers, and so tests can inject a go-billy memfs-backed backend
+// instead of a real on-disk repository.
+type GitBackend interface {
+	GetCurrentBranch() (string, error)
+	FetchTags() error
+	GetLatestTag(baseBranch string) (string, error)
+	GetChangedFiles(sinceCommit, pathFilter string) ([]string, error)
+	CheckoutBranch(branch string) error
+	AddWorkingTree(ref string) (string, func(), error)
+	RemoveWorkingTree(path string) error
+	BranchExists(branch string) bool
+	FetchBranch(branch string) error
+	PullBranch(branch string) error
+	HasChanges(filePath string) bool
+	CommitAndPush(filePath, message, branch string) error
+}
+
+// gitBackendEnvVar selects ExecBackend when set to "exec"; any other value,
+// including unset, selects GoGitBackend.
+const gitBackendEnvVar = "KUBEDOOP_GIT_BACKEND"
+
+// Git manages Git operations for detecting changes and managing branches,
+// delegating to a GitBackend so the implementation can be swapped without
+// touching any caller.
 type Git struct {
-	RepoPath string
+	GitBackend
 }
 
-// NewGit creates a new Git instance
+// NewGit creates a new Git instance rooted at repoPath, backed by
+// GoGitBackend unless KUBEDOOP_GIT_BACKEND=exec is set, in which case it
+// shells out to the git binary via ExecBackend instead.
 func NewGit(repoPath string) *Git {
-	return &Git{
+	if os.Getenv(gitBackendEnvVar) == "exec" {
+		return NewGitWithBackend(NewExecBackend(repoPath))
+	}
+	return NewGitWithBackend(NewGoGitBackend(repoPath))
+}
+
+// NewGitWithBackend creates a Git instance wrapping an explicit backend,
+// e.g. a GoGitBackend opened against a go-billy memfs repository in tests.
+func NewGitWithBackend(backend GitBackend) *Git {
+	return &Git{GitBackend: backend}
+}
+
+// ExecBackend implements GitBackend by shelling out to the git binary.
+type ExecBackend struct {
+	RepoPath string
+}
+
+// NewExecBackend creates an ExecBackend rooted at repoPath.
+func NewExecBackend(repoPath string) *ExecBackend {
+	return &ExecBackend{
 		RepoPath: repoPath,
 	}
 }
 
 // runGitCommand runs a git command and returns its output
-func (g *Git) runGitCommand(args ...string) (string, error) {
+func (g *ExecBackend) runGitCommand(args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = g.RepoPath
-	
+
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("git command failed: %w", err)
 	}
-	
+
 	return strings.TrimSpace(string(output)), nil
 }
 
 // GetCurrentBranch gets the current branch name
-func (g *Git) GetCurrentBranch() (string, error) {
+func (g *ExecBackend) GetCurrentBranch() (string, error) {
 	return g.runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
 }
 
 // FetchTags fetches tags from remote repository
-func (g *Git) FetchTags() error {
+func (g *ExecBackend) FetchTags() error {
 	_, err := g.runGitCommand("fetch", "--tags")
 	if err != nil {
 		log.Printf("Failed to fetch tags from remote: %v", err)
@@ -47,10 +101,10 @@ func (g *Git) FetchTags() error {
 }
 
 // GetLatestTag gets the latest tag or appropriate commit for comparison
-func (g *Git) GetLatestTag(baseBranch string) (string, error) {
+func (g *ExecBackend) GetLatestTag(baseBranch string) (string, error) {
 	// Fetch tags first
 	g.FetchTags()
-	
+
 	currentBranch, err := g.GetCurrentBranch()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
@@ -73,7 +127,7 @@ func (g *Git) GetLatestTag(baseBranch string) (string, error) {
 }
 
 // GetChangedFiles gets list of changed files since a commit
-func (g *Git) GetChangedFiles(sinceCommit, pathFilter string) ([]string, error) {
+func (g *ExecBackend) GetChangedFiles(sinceCommit, pathFilter string) ([]string, error) {
 	args := []string{"diff", "--find-renames", "--name-only", sinceCommit}
 	if pathFilter != "" {
 		args = append(args, "--", pathFilter)
@@ -92,13 +146,48 @@ func (g *Git) GetChangedFiles(sinceCommit, pathFilter string) ([]string, error)
 }
 
 // CheckoutBranch checks out a branch
-func (g *Git) CheckoutBranch(branch string) error {
+func (g *ExecBackend) CheckoutBranch(branch string) error {
 	_, err := g.runGitCommand("checkout", branch)
 	return err
 }
 
+// AddWorkingTree checks out ref into a new temporary worktree and returns
+// its path, along with a cleanup func that removes the worktree. Unlike
+// CheckoutBranch, this never touches the caller's working directory or
+// HEAD, so it's safe to call from a dirty working tree, from a CI job that
+// already has files staged, or concurrently against multiple refs - e.g.
+// to load a chart's Chart.yaml at both its old and new ref for a
+// semver-diff without ever checking out the old ref in place.
+func (g *ExecBackend) AddWorkingTree(ref string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "chart-release-manager-worktree-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create worktree dir: %w", err)
+	}
+
+	if _, err := g.runGitCommand("worktree", "add", "--detach", dir, ref); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to add worktree for %s: %w", ref, err)
+	}
+
+	cleanup := func() {
+		if err := g.RemoveWorkingTree(dir); err != nil {
+			log.Printf("Failed to remove worktree %s: %v", dir, err)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// RemoveWorkingTree removes a worktree previously created by
+// AddWorkingTree.
+func (g *ExecBackend) RemoveWorkingTree(path string) error {
+	if _, err := g.runGitCommand("worktree", "remove", "--force", path); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", path, err)
+	}
+	return os.RemoveAll(path)
+}
+
 // BranchExists checks if a branch exists on remote
-func (g *Git) BranchExists(branch string) bool {
+func (g *ExecBackend) BranchExists(branch string) bool {
 	output, err := g.runGitCommand("ls-remote", "--heads", "origin", branch)
 	if err != nil {
 		return false
@@ -107,25 +196,25 @@ func (g *Git) BranchExists(branch string) bool {
 }
 
 // FetchBranch fetches a branch from remote
-func (g *Git) FetchBranch(branch string) error {
+func (g *ExecBackend) FetchBranch(branch string) error {
 	_, err := g.runGitCommand("fetch", "origin", branch)
 	return err
 }
 
 // PullBranch pulls latest changes from a branch
-func (g *Git) PullBranch(branch string) error {
+func (g *ExecBackend) PullBranch(branch string) error {
 	_, err := g.runGitCommand("pull", "origin", branch)
 	return err
 }
 
 // HasChanges checks if a file has uncommitted changes
-func (g *Git) HasChanges(filePath string) bool {
+func (g *ExecBackend) HasChanges(filePath string) bool {
 	_, err := g.runGitCommand("diff", "--quiet", filePath)
 	return err != nil // Has changes if command fails
 }
 
 // CommitAndPush commits and pushes changes to a file
-func (g *Git) CommitAndPush(filePath, message, branch string) error {
+func (g *ExecBackend) CommitAndPush(filePath, message, branch string) error {
 	// Add file
 	if _, err := g.runGitCommand("add", filePath); err != nil {
 		return fmt.Errorf("failed to add file: %w", err)
@@ -143,3 +232,470 @@ func (g *Git) CommitAndPush(filePath, message, branch string) error {
 
 	return nil
 }
+
+// goGitCommitSignature is the identity GoGitBackend commits are authored
+// as. Unlike ExecBackend, which shells out to the git binary and relies
+// on its system/global config, go-git commits in-process and never reads
+// that config, so without an explicit signature every commit fails with
+// "author field is required".
+var goGitCommitSignature = &object.Signature{
+	Name:  "chart-release-manager",
+	Email: "chart-release-manager@users.noreply.github.com",
+}
+
+// GoGitBackend implements GitBackend in-process on top of go-git, so it
+// works without the git binary on $PATH and can be pointed at a go-billy
+// memfs repository in tests.
+type GoGitBackend struct {
+	repoPath string
+	repo     *gogit.Repository
+}
+
+// NewGoGitBackend creates a GoGitBackend rooted at repoPath. The
+// repository is opened lazily on first use.
+func NewGoGitBackend(repoPath string) *GoGitBackend {
+	return &GoGitBackend{repoPath: repoPath}
+}
+
+// NewGoGitBackendFromRepository wraps an already-open go-git repository,
+// e.g. one created against a go-billy memfs filesystem in tests.
+func NewGoGitBackendFromRepository(repo *gogit.Repository) *GoGitBackend {
+	return &GoGitBackend{repo: repo}
+}
+
+// open lazily opens the on-disk repository at g.repoPath.
+func (g *GoGitBackend) open() (*gogit.Repository, error) {
+	if g.repo != nil {
+		return g.repo, nil
+	}
+
+	repo, err := gogit.PlainOpen(g.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", g.repoPath, err)
+	}
+	g.repo = repo
+	return repo, nil
+}
+
+// GetCurrentBranch gets the current branch name
+func (g *GoGitBackend) GetCurrentBranch() (string, error) {
+	repo, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return head.Name().Short(), nil
+}
+
+// FetchTags fetches tags from the "origin" remote.
+func (g *GoGitBackend) FetchTags() error {
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	err = repo.Fetch(&gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+	})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
+		log.Printf("Failed to fetch tags from remote: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetLatestTag gets the latest tag or appropriate commit for comparison,
+// mirroring `git describe --tags --abbrev=0 HEAD~` with a merge-base
+// fallback.
+func (g *GoGitBackend) GetLatestTag(baseBranch string) (string, error) {
+	g.FetchTags()
+
+	repo, err := g.open()
+	if err != nil {
+		return "", err
+	}
+
+	currentBranch, err := g.GetCurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	if tag, err := g.nearestTagBeforeHead(repo); err == nil {
+		return tag, nil
+	}
+
+	if currentBranch == baseBranch {
+		return g.firstCommit(repo)
+	}
+	return g.mergeBase(repo, baseBranch)
+}
+
+// nearestTagBeforeHead walks the history reachable from HEAD~ (following
+// every parent, not just the first), and returns the name of the first tag it finds pointing at a
+// visited commit.
+func (g *GoGitBackend) nearestTagBeforeHead(repo *gogit.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD commit: %w", err)
+	}
+
+	parent, err := headCommit.Parents().Next()
+	if err != nil {
+		return "", fmt.Errorf("HEAD has no parent commit: %w", err)
+	}
+
+	tagsByHash := make(map[plumbing.Hash]string)
+	tagRefs, err := repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	if err := tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tagObj, err := repo.TagObject(hash); err == nil {
+			hash = tagObj.Target
+		}
+		tagsByHash[hash] = ref.Name().Short()
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to resolve tags: %w", err)
+	}
+	if len(tagsByHash) == 0 {
+		return "", fmt.Errorf("no tags found")
+	}
+
+	commitIter, err := repo.Log(&gogit.LogOptions{From: parent.Hash})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk commit history: %w", err)
+	}
+
+	var found string
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if name, ok := tagsByHash[c.Hash]; ok {
+			found = name
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("no reachable tag found")
+	}
+	return found, nil
+}
+
+// firstCommit returns the hash of the repository's first (root) commit
+// reachable from HEAD.
+func (g *GoGitBackend) firstCommit(repo *gogit.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	commitIter, err := repo.Log(&gogit.LogOptions{From: head.Hash()})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk commit history: %w", err)
+	}
+
+	var last *object.Commit
+	if err := commitIter.ForEach(func(c *object.Commit) error {
+		last = c
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if last == nil {
+		return "", fmt.Errorf("no commits found")
+	}
+	return last.Hash.String(), nil
+}
+
+// mergeBase returns the merge base of HEAD and the "origin/<baseBranch>"
+// remote-tracking branch.
+func (g *GoGitBackend) mergeBase(repo *gogit.Repository, baseBranch string) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD commit: %w", err)
+	}
+
+	baseRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", baseBranch), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve origin/%s: %w", baseBranch, err)
+	}
+	baseCommit, err := repo.CommitObject(baseRef.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s commit: %w", baseBranch, err)
+	}
+
+	bases, err := headCommit.MergeBase(baseCommit)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute merge base: %w", err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("no merge base found with %s", baseBranch)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// resolveCommit resolves rev (a commit hash or a tag name) to a commit object.
+func (g *GoGitBackend) resolveCommit(repo *gogit.Repository, rev string) (*object.Commit, error) {
+	if hash := plumbing.NewHash(rev); !hash.IsZero() {
+		if commit, err := repo.CommitObject(hash); err == nil {
+			return commit, nil
+		}
+	}
+
+	ref, err := repo.Tag(rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", rev, err)
+	}
+	hash := ref.Hash()
+	if tagObj, err := repo.TagObject(hash); err == nil {
+		hash = tagObj.Target
+	}
+	return repo.CommitObject(hash)
+}
+
+// GetChangedFiles gets the list of files under pathFilter changed since
+// sinceCommit, computed in-process via a tree diff rather than shelling
+// out to `git diff --name-only`.
+func (g *GoGitBackend) GetChangedFiles(sinceCommit, pathFilter string) ([]string, error) {
+	repo, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+
+	fromCommit, err := g.resolveCommit(repo, sinceCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", sinceCommit, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	toCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD commit: %w", err)
+	}
+
+	patch, err := fromCommit.Patch(toCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s against HEAD: %w", sinceCommit, err)
+	}
+
+	var files []string
+	for _, filePatch := range patch.FilePatches() {
+		from, to := filePatch.Files()
+		path := ""
+		if to != nil {
+			path = to.Path()
+		} else if from != nil {
+			path = from.Path()
+		}
+		if path == "" {
+			continue
+		}
+		if pathFilter != "" && !strings.HasPrefix(path, pathFilter) {
+			continue
+		}
+		files = append(files, path)
+	}
+
+	if len(files) == 0 {
+		return []string{}, nil
+	}
+	return files, nil
+}
+
+// CheckoutBranch checks out a branch in the caller's working tree.
+func (g *GoGitBackend) CheckoutBranch(branch string) error {
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch)}); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", branch, err)
+	}
+	return nil
+}
+
+// AddWorkingTree checks out ref into a new temporary directory via a local
+// clone, and returns its path along with a cleanup func that removes it.
+// Because the clone is a separate on-disk checkout, this never touches the
+// caller's working directory or HEAD.
+func (g *GoGitBackend) AddWorkingTree(ref string) (string, func(), error) {
+	repo, err := g.open()
+	if err != nil {
+		return "", nil, err
+	}
+
+	commit, err := g.resolveCommit(repo, ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	dir, err := os.MkdirTemp("", "chart-release-manager-worktree-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create worktree dir: %w", err)
+	}
+
+	cloned, err := gogit.PlainClone(dir, false, &gogit.CloneOptions{URL: g.repoPath})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to clone %s into worktree: %w", g.repoPath, err)
+	}
+
+	wt, err := cloned.Worktree()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := wt.Checkout(&gogit.CheckoutOptions{Hash: commit.Hash}); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to checkout %s in worktree: %w", ref, err)
+	}
+
+	cleanup := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Failed to remove worktree %s: %v", dir, err)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// RemoveWorkingTree removes a worktree previously created by
+// AddWorkingTree.
+func (g *GoGitBackend) RemoveWorkingTree(path string) error {
+	return os.RemoveAll(path)
+}
+
+// BranchExists checks if a branch exists on remote
+func (g *GoGitBackend) BranchExists(branch string) bool {
+	repo, err := g.open()
+	if err != nil {
+		return false
+	}
+
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return false
+	}
+
+	refs, err := remote.List(&gogit.ListOptions{})
+	if err != nil {
+		return false
+	}
+
+	target := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == target {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchBranch fetches a branch from remote
+func (g *GoGitBackend) FetchBranch(branch string) error {
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch))
+	err = repo.Fetch(&gogit.FetchOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to fetch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// PullBranch pulls latest changes from a branch
+func (g *GoGitBackend) PullBranch(branch string) error {
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	err = wt.Pull(&gogit.PullOptions{RemoteName: "origin", ReferenceName: plumbing.NewBranchReferenceName(branch)})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to pull %s: %w", branch, err)
+	}
+	return nil
+}
+
+// HasChanges checks if a file has uncommitted changes
+func (g *GoGitBackend) HasChanges(filePath string) bool {
+	repo, err := g.open()
+	if err != nil {
+		return false
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false
+	}
+
+	s, ok := status[filePath]
+	return ok && (s.Worktree != gogit.Unmodified || s.Staging != gogit.Unmodified)
+}
+
+// CommitAndPush commits and pushes changes to a file
+func (g *GoGitBackend) CommitAndPush(filePath, message, branch string) error {
+	repo, err := g.open()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if _, err := wt.Add(filePath); err != nil {
+		return fmt.Errorf("failed to add file %s: %w", filePath, err)
+	}
+
+	if _, err := wt.Commit(message, &gogit.CommitOptions{Author: goGitCommitSignature}); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
+	if err := repo.Push(&gogit.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}}); err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestGoGitBackendAddWorkingTreeChecksOutCommit(t *testing.T) {
+	repoDir := t.TempDir()
+
+	repo, err := gogit.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "file.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("failed to write file.txt: %v", err)
+	}
+	if _, err := wt.Add("file.txt"); err != nil {
+		t.Fatalf("failed to add file.txt: %v", err)
+	}
+	commit, err := wt.Commit("add file", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	backend := NewGoGitBackend(repoDir)
+	dir, cleanup, err := backend.AddWorkingTree(commit.String())
+	if err != nil {
+		t.Fatalf("AddWorkingTree failed: %v", err)
+	}
+	defer cleanup()
+
+	data, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	if err != nil {
+		t.Fatalf("failed to read file.txt from worktree %s: %v", dir, err)
+	}
+	if string(data) != "hello\n" {
+		t.Errorf("expected worktree file.txt to contain %q, got %q", "hello\n", data)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected worktree dir %s to be removed after cleanup, stat err: %v", dir, err)
+	}
+}
+
+func TestGoGitBackendCommitAndPushPushesToRemote(t *testing.T) {
+	base := t.TempDir()
+	remoteDir := filepath.Join(base, "remote.git")
+	workDir := filepath.Join(base, "work")
+	branch := "main"
+
+	if _, err := gogit.PlainInit(remoteDir, true); err != nil {
+		t.Fatalf("failed to init bare remote: %v", err)
+	}
+
+	workRepo, err := gogit.PlainInit(workDir, false)
+	if err != nil {
+		t.Fatalf("failed to init work repo: %v", err)
+	}
+	wt, err := workRepo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	seedPath := filepath.Join(workDir, "seed.txt")
+	if err := os.WriteFile(seedPath, []byte("seed\n"), 0644); err != nil {
+		t.Fatalf("failed to write seed file: %v", err)
+	}
+	if _, err := wt.Add("seed.txt"); err != nil {
+		t.Fatalf("failed to add seed file: %v", err)
+	}
+	if _, err := wt.Commit("seed commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com"},
+	}); err != nil {
+		t.Fatalf("failed to create seed commit: %v", err)
+	}
+
+	head, err := workRepo.Head()
+	if err != nil {
+		t.Fatalf("failed to resolve HEAD: %v", err)
+	}
+	if head.Name().Short() != branch {
+		if err := wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}); err != nil {
+			t.Fatalf("failed to create branch %s: %v", branch, err)
+		}
+	}
+
+	if _, err := workRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("failed to add origin remote: %v", err)
+	}
+	refSpec := config.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch)
+	if err := workRepo.Push(&gogit.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}}); err != nil {
+		t.Fatalf("failed to push seed commit: %v", err)
+	}
+
+	remoteRepo, err := gogit.PlainOpen(remoteDir)
+	if err != nil {
+		t.Fatalf("failed to open bare remote: %v", err)
+	}
+	before, err := remoteRepo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		t.Fatalf("failed to resolve %s on remote: %v", branch, err)
+	}
+
+	backend := NewGoGitBackend(workDir)
+	filePath := filepath.Join(workDir, "index.yaml")
+	if err := os.WriteFile(filePath, []byte("entries: {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write index.yaml: %v", err)
+	}
+	if err := backend.CommitAndPush("index.yaml", "update index", branch); err != nil {
+		t.Fatalf("CommitAndPush failed: %v", err)
+	}
+
+	after, err := remoteRepo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		t.Fatalf("failed to resolve %s on remote: %v", branch, err)
+	}
+	if after.Hash() == before.Hash() {
+		t.Fatalf("expected remote %s ref to move past %s, but it didn't change", branch, before.Hash())
+	}
+}
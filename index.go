@@ -32,7 +32,7 @@ type ChartIndexManager struct {
 // NewChartIndexManager creates a new ChartIndexManager
 func NewChartIndexManager(git *Git, pagesBranch string) *ChartIndexManager {
 	return &ChartIndexManager{
-		GitOps:      gitOps,
+		Git:         git,
 		PagesBranch: pagesBranch,
 	}
 }
@@ -40,29 +40,29 @@ func NewChartIndexManager(git *Git, pagesBranch string) *ChartIndexManager {
 // PreparePagesBranch prepares the pages branch for index operations
 func (cim *ChartIndexManager) PreparePagesBranch() error {
 	// Save current branch
-	currentBranch, err := cim.GitOps.GetCurrentBranch()
+	currentBranch, err := cim.Git.GetCurrentBranch()
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 	cim.OriginalBranch = currentBranch
 
 	// Check if pages branch exists
-	if !cim.GitOps.BranchExists(cim.PagesBranch) {
+	if !cim.Git.BranchExists(cim.PagesBranch) {
 		log.Printf("%s branch does not exist. Skipping index cleanup.", cim.PagesBranch)
 		return fmt.Errorf("pages branch does not exist")
 	}
 
 	// Fetch and checkout pages branch
-	if err := cim.GitOps.FetchBranch(cim.PagesBranch); err != nil {
+	if err := cim.Git.FetchBranch(cim.PagesBranch); err != nil {
 		return fmt.Errorf("failed to fetch %s branch: %w", cim.PagesBranch, err)
 	}
 
-	if err := cim.GitOps.CheckoutBranch(cim.PagesBranch); err != nil {
+	if err := cim.Git.CheckoutBranch(cim.PagesBranch); err != nil {
 		return fmt.Errorf("failed to checkout %s branch: %w", cim.PagesBranch, err)
 	}
 
 	// Pull latest changes
-	if err := cim.GitOps.PullBranch(cim.PagesBranch); err != nil {
+	if err := cim.Git.PullBranch(cim.PagesBranch); err != nil {
 		log.Printf("Failed to pull latest changes from %s: %v", cim.PagesBranch, err)
 	}
 
@@ -79,7 +79,7 @@ func (cim *ChartIndexManager) PreparePagesBranch() error {
 // RestoreOriginalBranch restores the original branch
 func (cim *ChartIndexManager) RestoreOriginalBranch() error {
 	if cim.OriginalBranch != "" {
-		return cim.GitOps.CheckoutBranch(cim.OriginalBranch)
+		return cim.Git.CheckoutBranch(cim.OriginalBranch)
 	}
 	return nil
 }
@@ -180,7 +180,7 @@ func (cim *ChartIndexManager) CleanMultipleChartIndexes(charts []*ChartInfo) err
 		log.Printf("Removed from index.yaml: %v", removedCharts)
 
 		// Commit and push if there are changes
-		if cim.GitOps.HasChanges("index.yaml") {
+		if cim.Git.HasChanges("index.yaml") {
 			var commitMsg string
 			if len(removedCharts) == 1 {
 				commitMsg = fmt.Sprintf("Remove %s from index", removedCharts[0])
@@ -188,7 +188,7 @@ func (cim *ChartIndexManager) CleanMultipleChartIndexes(charts []*ChartInfo) err
 				commitMsg = fmt.Sprintf("Remove %d chart versions from index", len(removedCharts))
 			}
 
-			if err := cim.GitOps.CommitAndPush("index.yaml", commitMsg, cim.PagesBranch); err != nil {
+			if err := cim.Git.CommitAndPush("index.yaml", commitMsg, cim.PagesBranch); err != nil {
 				return fmt.Errorf("failed to push index.yaml changes: %w", err)
 			}
 			log.Printf("Successfully pushed index.yaml changes to %s branch", cim.PagesBranch)
@@ -228,9 +228,9 @@ func (cim *ChartIndexManager) CleanAllChartIndex() error {
 	log.Printf("Cleared all entries from index.yaml")
 
 	// Commit and push changes
-	if cim.GitOps.HasChanges("index.yaml") {
+	if cim.Git.HasChanges("index.yaml") {
 		commitMsg := "Clear all chart entries from index"
-		if err := cim.GitOps.CommitAndPush("index.yaml", commitMsg, cim.PagesBranch); err != nil {
+		if err := cim.Git.CommitAndPush("index.yaml", commitMsg, cim.PagesBranch); err != nil {
 			return fmt.Errorf("failed to push index.yaml changes: %w", err)
 		}
 		log.Printf("Successfully pushed index.yaml changes to %s branch", cim.PagesBranch)
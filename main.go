@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -165,7 +166,7 @@ func deleteSpecificReleases(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get changed charts
-	changedCharts, err := chartManager.GetChangedCharts(changedFiles, versionPattern)
+	changedCharts, err := chartManager.GetChangedCharts(changedFiles, versionPattern, true)
 	if err != nil {
 		return fmt.Errorf("failed to get changed charts: %w", err)
 	}
@@ -181,6 +182,25 @@ func deleteSpecificReleases(cmd *cobra.Command, args []string) error {
 	}
 	log.Printf("The following charts have changed, and their releases will be deleted: %v", chartNames)
 
+	// Load latestTag into a worktree so each chart's old Chart.yaml can be
+	// compared against its current one for a semver bump, without ever
+	// checking out latestTag in the primary working directory.
+	baseWorktree, cleanupWorktree, err := git.AddWorkingTree(latestTag)
+	if err != nil {
+		return fmt.Errorf("failed to add worktree for %s: %w", latestTag, err)
+	}
+	defer cleanupWorktree()
+
+	for _, chart := range changedCharts {
+		oldChartPath := filepath.Join(baseWorktree, chart.Path)
+		bump, err := chartManager.CompareVersions(oldChartPath, chart.Path)
+		if err != nil {
+			log.Printf("Could not classify version bump for %s: %v", chart.Name, err)
+			continue
+		}
+		log.Printf("%s: %s bump (now %s)", chart.Name, bump, chart.Version)
+	}
+
 	// Delete releases for each changed chart
 	for _, chart := range changedCharts {
 		releaseName := fmt.Sprintf("%s-%s", chart.Name, chart.Version)